@@ -0,0 +1,492 @@
+package main
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/report/generate"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/events"
+	"CompetitionLogger/pkg/logger"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const programName = "competition-logger"
+
+// followInterval is how often live --follow polls the events file for new lines
+const followInterval = 200 * time.Millisecond
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage marks errors in the command line itself
+var errUsage = errors.New("usage")
+
+type options struct {
+	config     string
+	events     string
+	roster     string
+	format     string
+	output     string
+	categoryBy string
+	columns    string
+	template   string
+	locale     string
+	locales    string
+	strict     bool
+	follow     bool
+}
+
+type command struct {
+	summary string
+	// formats are the accepted values of --format, the first one is the default
+	formats []string
+	run     func(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"report":   {summary: "Print the results table", formats: []string{"text", "json", "csv", "tsv", "html"}, run: runReport},
+	"log":      {summary: "Print the log of incoming and outgoing events", formats: []string{"text", "jsonl", "csv", "tsv"}, run: runLog},
+	"validate": {summary: "Check the events and exit non-zero on any problem", formats: []string{"text"}, run: runValidate},
+	"replay":   {summary: "Print the event log followed by the results table", formats: []string{"text"}, run: runReplay},
+	"live":     {summary: "Print the event log as the events arrive and the results table once they end", formats: []string{"text"}, run: runLive},
+}
+
+// run executes the command line args and returns the exit code
+func run(ctx context.Context, args []string, stdin *os.File, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		usage(stderr)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts options
+	flags.StringVar(&opts.config, "config", os.Getenv("CONFIG_PATH"), "race config `file` (default $CONFIG_PATH)")
+	flags.StringVar(&opts.events, "events", os.Getenv("EVENTS_PATH"), "events `file`, - or empty for stdin (default $EVENTS_PATH)")
+	flags.StringVar(&opts.roster, "roster", os.Getenv("ROSTER_PATH"), "optional competitor roster `file`, CSV or JSON (default $ROSTER_PATH)")
+	flags.StringVar(&opts.format, "format", cmd.formats[0], "output `format`: "+strings.Join(cmd.formats, ", "))
+	flags.StringVar(&opts.output, "output", "", "write to `file` instead of stdout")
+	flags.StringVar(&opts.categoryBy, "category-by", "", "split results by roster `attribute`: category, gender or club")
+	flags.StringVar(&opts.columns, "columns", "", "comma separated `columns` of the csv and tsv formats, groups laps, penalties and shooting expand to every lap, penalty loop and firing line (default all)")
+	flags.StringVar(&opts.template, "template", "", "html template `file` redefining the page or parts of it (default built in)")
+	flags.StringVar(&opts.locale, "locale", envOr("LOCALE", messages.DefaultLocale), "`language` of the text and html output, loaded from the locales directory unless en (default $LOCALE or en)")
+	flags.StringVar(&opts.locales, "locales", envOr("LOCALES_PATH", "locales"), "`directory` of the message catalogues named <locale>.json (default $LOCALES_PATH or locales)")
+	flags.BoolVar(&opts.strict, "strict", os.Getenv("STRICT_EVENTS") == "true", "stop at the first malformed event line")
+	flags.BoolVar(&opts.follow, "follow", false, "keep reading the events file as it grows until interrupted (live only)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags]\n\n%s.\n\nFlags:\n", programName, name, cmd.summary)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		return exitUsage
+	}
+	if opts.follow && name != "live" {
+		fmt.Fprintf(stderr, "%s: --follow only applies to live\n", name)
+		return exitUsage
+	}
+	if opts.categoryBy != "" && opts.format != "text" && opts.format != "html" {
+		fmt.Fprintf(stderr, "%s: --category-by only applies to the text and html formats, %s carries the attributes of every competitor\n", name, opts.format)
+		return exitUsage
+	}
+	if _, ok := (roster.Competitor{}).Attribute(opts.categoryBy); opts.categoryBy != "" && !ok {
+		fmt.Fprintf(stderr, "%s: unknown competitor attribute %q\n", name, opts.categoryBy)
+		return exitUsage
+	}
+	if opts.columns != "" && opts.format != "csv" && opts.format != "tsv" {
+		fmt.Fprintf(stderr, "%s: --columns only applies to the csv and tsv formats\n", name)
+		return exitUsage
+	}
+	if opts.template != "" && opts.format != "html" {
+		fmt.Fprintf(stderr, "%s: --template only applies to the html format\n", name)
+		return exitUsage
+	}
+
+	if err := execute(ctx, cmd, opts, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		if errors.Is(err, errUsage) {
+			return exitUsage
+		}
+		return exitFailure
+	}
+	return exitOK
+}
+
+// execute runs cmd with its output redirected to --output when given
+func execute(ctx context.Context, cmd command, opts options, stdin *os.File, stdout io.Writer) error {
+	if !slices.Contains(cmd.formats, opts.format) {
+		return fmt.Errorf("%w: unknown format %q, want one of %s", errUsage, opts.format, strings.Join(cmd.formats, ", "))
+	}
+	if opts.config == "" {
+		return fmt.Errorf("%w: --config is required", errUsage)
+	}
+
+	if opts.output == "" {
+		return cmd.run(ctx, opts, stdin, stdout)
+	}
+
+	// The output goes to a temporary file next to it that replaces it once the
+	// command succeeded, so a failing run leaves an existing file as it was
+	outputFile, err := os.CreateTemp(filepath.Dir(opts.output), "."+filepath.Base(opts.output)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(outputFile.Name())
+	err = errors.Join(outputFile.Chmod(0o644), cmd.run(ctx, opts, stdin, outputFile), outputFile.Close())
+	if err != nil {
+		return err
+	}
+	return os.Rename(outputFile.Name(), opts.output)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", programName)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> --help' for the flags of a command.\n", programName)
+}
+
+// envOr is the value of the environment variable name, fallback when unset
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// race is everything the commands work on
+type race struct {
+	config      config.Race
+	store       *events.EventStore
+	parseErrors []*events.ParseError
+	competitors *roster.Roster
+	messages    *messages.Catalog
+}
+
+// textOptions name the competitors by the roster and translate by the catalogue
+func (r *race) textOptions() generate.Options {
+	return generate.Options{Competitors: r.competitors, Messages: r.messages}
+}
+
+// load reads the config, the roster and the events, from stdin when no events file is given
+func load(ctx context.Context, opts options, stdin *os.File) (*race, error) {
+	r, err := prepare(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsFile, closeEvents, err := openEvents(ctx, opts, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer closeEvents()
+	r.store, r.parseErrors = events.ParseEvents(ctx, eventsFile, parseOptions(r.config, opts))
+	// Parsing stops at the first error in strict mode and at a line it cannot read in any mode
+	if n := len(r.parseErrors); n > 0 && (opts.strict || errors.Is(r.parseErrors[n-1], events.ErrRead)) {
+		return nil, fmt.Errorf("parsing events: %w", r.parseErrors[n-1])
+	}
+	return r, nil
+}
+
+// prepare reads everything but the events
+func prepare(ctx context.Context, opts options) (*race, error) {
+	configBytes, err := config.LoadConfig(opts.config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+	raceConfig, err := config.ParseConfig(configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("config %s:\n%w", opts.config, err)
+	}
+	if raceConfig.StartMode == config.StartPursuit && raceConfig.PreviousResult != "" {
+		handicaps, err := config.LoadHandicaps(raceConfig.PreviousResult)
+		if err != nil {
+			return nil, fmt.Errorf("loading pursuit handicaps: %w", err)
+		}
+		raceConfig.Handicaps = handicaps
+	}
+
+	var competitors *roster.Roster
+	if opts.roster != "" {
+		if competitors, err = roster.Load(ctx, opts.roster); err != nil {
+			return nil, fmt.Errorf("loading roster: %w", err)
+		}
+	}
+
+	var catalog *messages.Catalog
+	if opts.locale != messages.DefaultLocale {
+		if catalog, err = messages.Load(ctx, filepath.Join(opts.locales, opts.locale+".json")); err != nil {
+			return nil, fmt.Errorf("loading locale %s: %w", opts.locale, err)
+		}
+	}
+
+	return &race{config: raceConfig, competitors: competitors, messages: catalog}, nil
+}
+
+// openEvents opens the events file, stdin when none is given. The returned
+// close func leaves stdin open.
+func openEvents(ctx context.Context, opts options, stdin *os.File) (*os.File, func() error, error) {
+	if opts.events == "" || opts.events == "-" {
+		return stdin, func() error { return nil }, nil
+	}
+	eventsFile := events.LoadEvents(ctx, opts.events)
+	if eventsFile == nil {
+		return nil, nil, fmt.Errorf("cannot open events %s", opts.events)
+	}
+	return eventsFile, eventsFile.Close, nil
+}
+
+func parseOptions(raceConfig config.Race, opts options) events.Options {
+	return events.Options{
+		Strict:      opts.strict,
+		FiringLines: raceConfig.FiringLines,
+		Targets:     raceConfig.Targets(),
+	}
+}
+
+func runReport(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error {
+	r, err := load(ctx, opts, stdin)
+	if err != nil {
+		return err
+	}
+	worker.GenerateOutgoing(r.config, r.store)
+	return writeReport(ctx, r, opts, stdout)
+}
+
+func runLog(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error {
+	r, err := load(ctx, opts, stdin)
+	if err != nil {
+		return err
+	}
+	worker.GenerateOutgoing(r.config, r.store)
+	switch opts.format {
+	case "jsonl":
+		return generate.EncodeLogJSONLines(stdout, r.store.All(), r.competitors)
+	case "csv":
+		return generate.EncodeLogTable(stdout, r.store.All(), r.competitors, 0)
+	case "tsv":
+		return generate.EncodeLogTable(stdout, r.store.All(), r.competitors, '\t')
+	}
+	return writeLog(r, stdout)
+}
+
+func runReplay(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error {
+	r, err := load(ctx, opts, stdin)
+	if err != nil {
+		return err
+	}
+	worker.GenerateOutgoing(r.config, r.store)
+	if err := writeLog(r, stdout); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(stdout); err != nil {
+		return err
+	}
+	return writeReport(ctx, r, opts, stdout)
+}
+
+// runValidate lists every malformed line, unknown competitor and impossible event
+func runValidate(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error {
+	r, err := load(ctx, opts, stdin)
+	if err != nil {
+		return err
+	}
+
+	incoming := r.store.Len()
+	problems := 0
+	for _, parseErr := range r.parseErrors {
+		fmt.Fprintf(stdout, "line %d column %d: %v\n", parseErr.Line, parseErr.Column, parseErr.Err)
+		problems++
+	}
+	if r.competitors != nil {
+		for _, competitorID := range r.competitors.Unknown(r.store.All()) {
+			fmt.Fprintf(stdout, "competitor(%d): missing from the roster\n", competitorID)
+			problems++
+		}
+	}
+
+	worker.GenerateOutgoing(r.config, r.store)
+	reports, err := generate.ReportTable(r.config, r.store.ByCompetitor())
+	if err != nil {
+		fmt.Fprintf(stdout, "%v\n", err)
+		problems++
+	}
+	violations := generate.FormatViolations(reports)
+	fmt.Fprint(stdout, violations)
+	problems += strings.Count(violations, "\n")
+
+	if problems > 0 {
+		return fmt.Errorf("%d problems found", problems)
+	}
+	_, err = fmt.Fprintf(stdout, "%d events, no problems found\n", incoming)
+	return err
+}
+
+// runLive follows the events as they are written, printing every log line as soon
+// as its event is known, and reports once the input ends or --follow is interrupted
+func runLive(ctx context.Context, opts options, stdin *os.File, stdout io.Writer) error {
+	r, err := prepare(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if opts.follow && (opts.events == "" || opts.events == "-") {
+		return fmt.Errorf("%w: --follow needs an --events file", errUsage)
+	}
+
+	eventsFile, closeEvents, err := openEvents(ctx, opts, stdin)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
+	var source io.Reader = eventsFile
+	if opts.follow {
+		// An interrupt ends following the file and the results are printed, a
+		// second one kills the program as usual
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		context.AfterFunc(ctx, stop)
+		source = events.Tail(ctx, eventsFile, followInterval)
+	}
+
+	live := worker.NewLive(r.config)
+	writeEvents := func(applied []events.Event) error {
+		for _, event := range applied {
+			if _, err := fmt.Fprintln(stdout, generate.Log(event, r.textOptions())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for event, parseErr := range events.Stream(ctx, source, parseOptions(r.config, opts)) {
+		if parseErr != nil {
+			if opts.strict || errors.Is(parseErr, events.ErrRead) {
+				return fmt.Errorf("parsing events: %w", parseErr)
+			}
+			continue
+		}
+		if err := writeEvents(live.Apply(event)); err != nil {
+			return err
+		}
+	}
+	if err := writeEvents(live.Close()); err != nil {
+		return err
+	}
+
+	r.store = live.Store()
+	if _, err := fmt.Fprintln(stdout); err != nil {
+		return err
+	}
+	return writeReport(ctx, r, opts, stdout)
+}
+
+func writeLog(r *race, w io.Writer) error {
+	for generatedLog := range generate.Logs(r.store.All(), r.textOptions()) {
+		if _, err := fmt.Fprintln(w, generatedLog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeReport(ctx context.Context, r *race, opts options, w io.Writer) error {
+	reports, err := generate.ReportTable(r.config, r.store.ByCompetitor())
+	if err != nil {
+		return fmt.Errorf("processing competitors: %w", err)
+	}
+	if violations := generate.FormatViolations(reports); violations != "" {
+		logger.GetFromContext(ctx).Warn("inconsistent events", zap.String("violations", violations))
+	}
+
+	var teams []worker.TeamReport
+	if len(r.config.Teams) > 0 {
+		if teams, err = generate.RelayTable(r.config, r.store.ByCompetitor()); err != nil {
+			return fmt.Errorf("processing relay teams: %w", err)
+		}
+	}
+
+	switch opts.format {
+	case "json":
+		return generate.EncodeJSON(w, reports, teams, r.competitors)
+	case "csv", "tsv":
+		tableOpts := generate.TableOptions{}
+		if opts.format == "tsv" {
+			tableOpts.Comma = '\t'
+		}
+		if opts.columns != "" {
+			tableOpts.Columns = strings.Split(opts.columns, ",")
+		}
+		err := generate.EncodeTable(w, reports, r.competitors, tableOpts)
+		if errors.Is(err, generate.ErrUnknownColumn) {
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+		return err
+	case "html":
+		htmlOpts := generate.HTMLOptions{Options: r.textOptions(), CategoryBy: opts.categoryBy}
+		if opts.template != "" {
+			if htmlOpts.Template, err = generate.ParseHTMLTemplate(opts.template); err != nil {
+				return fmt.Errorf("loading template: %w", err)
+			}
+		}
+		return generate.RenderHTML(w, reports, teams, htmlOpts)
+	}
+
+	table := generate.FormatReport(reports, r.textOptions())
+	if opts.categoryBy != "" {
+		if table, err = generate.FormatCategories(reports, opts.categoryBy, r.textOptions()); err != nil {
+			return fmt.Errorf("%w: %w", errUsage, err)
+		}
+	}
+	if _, err := fmt.Fprint(w, table); err != nil {
+		return err
+	}
+
+	if len(teams) > 0 {
+		if _, err := fmt.Fprint(w, "\n"+generate.FormatRelay(teams, r.textOptions())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
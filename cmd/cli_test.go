@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testConfig = `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:30"}`
+
+const testEvents = `[09:05:59.867] 1 1
+[09:15:00.841] 2 1 10:00:00.000
+[10:00:01.005] 4 1
+[10:15:00.000] 10 1
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	configPath := writeFile(t, "config.json", testConfig)
+	eventsPath := writeFile(t, "events", testEvents)
+	brokenPath := writeFile(t, "broken", testEvents+"[10:16:00.000] 6 1 9\n")
+	rosterPath := writeFile(t, "roster.csv", "id,name\n1,Anna Berg\n")
+	templatePath := writeFile(t, "venue.html.tmpl", `{{define "title"}}Venue{{end}}`)
+	localePath := writeFile(t, "de.json", `{"status.Finished": "Im Ziel", "event.33": "[%[1]s] Teilnehmer(%[2]s) ist im Ziel"}`)
+	longPath := writeFile(t, "long", testEvents+"[10:16:00.000] 11 1 "+strings.Repeat("x", 1<<16)+"\n")
+	undrawnPath := writeFile(t, "undrawn", "[09:05:59.867] 1 1\n[10:00:01.005] 4 1\n")
+	previousPath := writeFile(t, "previous.csv", "id,status,total_time\n2,Finished,00:20:00.000\n")
+	pursuitPath := writeFile(t, "pursuit.json", `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:30", "startMode": "pursuit", "previousResult": "`+previousPath+`"}`)
+	invalidPath := writeFile(t, "invalid.json", `{"laps": 0, "lapLen": 3000, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`)
+
+	type content struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantOutput string
+		wantErr    string
+	}
+
+	tests := []content{
+		{
+			name:       "report",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath},
+			wantOutput: "[Finished] 1 [{00:14:58.995, 3.337}] {,} 0/0\n",
+		},
+		{
+			name:       "report with roster",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath, "--roster", rosterPath},
+			wantOutput: "[Finished] 1 Anna Berg [{00:14:58.995, 3.337}] {,} 0/0\n",
+		},
+		{
+			name:       "report json",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath, "--format", "json"},
+			wantOutput: `"schemaVersion": 1`,
+		},
+		{
+			name:       "report csv",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath, "--roster", rosterPath, "--format", "csv", "--columns", "place,name,laps"},
+			wantOutput: "place,name,lap1_time,lap1_speed\n1,Anna Berg,00:14:58.995,3.337\n",
+		},
+		{
+			name:     "unknown column",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--format", "tsv", "--columns", "place,shoe_size"},
+			wantCode: exitUsage,
+			wantErr:  `unknown column "shoe_size"`,
+		},
+		{
+			name:     "columns without csv",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--columns", "place"},
+			wantCode: exitUsage,
+			wantErr:  "--columns only applies to the csv and tsv formats",
+		},
+		{
+			name:       "report html",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath, "--roster", rosterPath, "--format", "html", "--template", templatePath},
+			wantOutput: "<title>Venue</title>",
+		},
+		{
+			name:     "template without html",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--template", templatePath},
+			wantCode: exitUsage,
+			wantErr:  "--template only applies to the html format",
+		},
+		{
+			name:     "category-by with json",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--format", "json", "--category-by", "club"},
+			wantCode: exitUsage,
+			wantErr:  "--category-by only applies to the text and html formats",
+		},
+		{
+			name:     "unknown attribute",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--category-by", "shoe"},
+			wantCode: exitUsage,
+			wantErr:  `unknown competitor attribute "shoe"`,
+		},
+		{
+			name:       "log jsonl",
+			args:       []string{"log", "--config", configPath, "--events", eventsPath, "--format", "jsonl"},
+			wantOutput: `{"time":"10:15:00.000","eventId":33,"event":"Finished","competitorId":1,"source":"generated","message":"[10:15:00.000] The competitor(1) has finished"}`,
+		},
+		{
+			name:       "log csv",
+			args:       []string{"log", "--config", configPath, "--events", eventsPath, "--roster", rosterPath, "--format", "csv"},
+			wantOutput: "09:05:59.867,1,Registered,1,Anna Berg,,,,,incoming,[09:05:59.867] The competitor(Anna Berg) registered\n",
+		},
+		{
+			name:       "report locale",
+			args:       []string{"report", "--config", configPath, "--events", eventsPath, "--locale", "de", "--locales", filepath.Dir(localePath)},
+			wantOutput: "[Im Ziel] 1 [{00:14:58.995, 3.337}] {,} 0/0\n",
+		},
+		{
+			name:       "log locale",
+			args:       []string{"log", "--config", configPath, "--events", eventsPath, "--locale", "de", "--locales", filepath.Dir(localePath)},
+			wantOutput: "[10:15:00.000] Teilnehmer(1) ist im Ziel\n",
+		},
+		{
+			name:     "missing locale",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--locale", "fr", "--locales", filepath.Dir(localePath)},
+			wantCode: exitFailure,
+			wantErr:  "loading locale fr",
+		},
+		{
+			name:     "log json",
+			args:     []string{"log", "--config", configPath, "--events", eventsPath, "--format", "json"},
+			wantCode: exitUsage,
+			wantErr:  `unknown format "json"`,
+		},
+		{
+			name:       "log",
+			args:       []string{"log", "--config", configPath, "--events", eventsPath},
+			wantOutput: "[09:05:59.867] The competitor(1) registered\n",
+		},
+		{
+			name:       "replay",
+			args:       []string{"replay", "--config", configPath, "--events", eventsPath},
+			wantOutput: "[10:15:00.000] The competitor(1) has finished\n\n[Finished] 1",
+		},
+		{
+			name:       "validate",
+			args:       []string{"validate", "--config", configPath, "--events", eventsPath},
+			wantOutput: "4 events, no problems found\n",
+		},
+		{
+			name:       "validate broken",
+			args:       []string{"validate", "--config", configPath, "--events", brokenPath},
+			wantCode:   exitFailure,
+			wantOutput: "line 5 column 20: bad extra param",
+			wantErr:    "1 problems found",
+		},
+		{
+			name:     "validate long line",
+			args:     []string{"validate", "--config", configPath, "--events", longPath},
+			wantCode: exitFailure,
+			wantErr:  "line 5: cannot read events: bufio.Scanner: token too long",
+		},
+		{
+			name:     "report long line",
+			args:     []string{"report", "--config", configPath, "--events", longPath},
+			wantCode: exitFailure,
+			wantErr:  "cannot read events",
+		},
+		{
+			name:     "live long line",
+			args:     []string{"live", "--config", configPath, "--events", longPath},
+			wantCode: exitFailure,
+			wantErr:  "cannot read events",
+		},
+		{
+			name:     "report without handicap",
+			args:     []string{"report", "--config", pursuitPath, "--events", undrawnPath},
+			wantCode: exitFailure,
+			wantErr:  "competitor 1: no handicap in the previous result",
+		},
+		{
+			name:     "live without handicap",
+			args:     []string{"live", "--config", pursuitPath, "--events", undrawnPath},
+			wantCode: exitFailure,
+			wantErr:  "competitor 1: no handicap in the previous result",
+		},
+		{
+			name:     "strict",
+			args:     []string{"report", "--config", configPath, "--events", brokenPath, "--strict"},
+			wantCode: exitFailure,
+			wantErr:  "parsing events",
+		},
+		{
+			name:     "missing config",
+			args:     []string{"report", "--config", filepath.Join(t.TempDir(), "missing.json"), "--events", eventsPath},
+			wantCode: exitFailure,
+			wantErr:  "cannot read config",
+		},
+		{
+			name:     "invalid config",
+			args:     []string{"report", "--config", invalidPath, "--events", eventsPath},
+			wantCode: exitFailure,
+			wantErr:  "laps must be positive, got 0",
+		},
+		{
+			name:     "missing events",
+			args:     []string{"report", "--config", configPath, "--events", filepath.Join(t.TempDir(), "missing")},
+			wantCode: exitFailure,
+			wantErr:  "cannot open events",
+		},
+		{
+			name:       "live",
+			args:       []string{"live", "--config", configPath, "--events", eventsPath},
+			wantOutput: "[10:15:00.000] The competitor(1) has finished\n\n[Finished] 1",
+		},
+		{
+			name:     "follow stdin",
+			args:     []string{"live", "--config", configPath, "--follow"},
+			wantCode: exitUsage,
+			wantErr:  "--follow needs an --events file",
+		},
+		{
+			name:     "follow without live",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--follow"},
+			wantCode: exitUsage,
+			wantErr:  "--follow only applies to live",
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"report", "--config", configPath, "--events", eventsPath, "--format", "xml"},
+			wantCode: exitUsage,
+			wantErr:  `unknown format "xml"`,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"print"},
+			wantCode: exitUsage,
+			wantErr:  `unknown command "print"`,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"report", "--colour"},
+			wantCode: exitUsage,
+			wantErr:  "flag provided but not defined",
+		},
+		{
+			name:     "no command",
+			wantCode: exitUsage,
+			wantErr:  "Usage:",
+		},
+		{
+			name:       "help",
+			args:       []string{"--help"},
+			wantOutput: "Commands:",
+		},
+		{
+			name:    "command help",
+			args:    []string{"report", "--help"},
+			wantErr: "-output file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(ctx, tt.args, nil, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d, stderr %q", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("run() output = %q, want it to contain %q", stdout.String(), tt.wantOutput)
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("run() stderr = %q, want it to contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunStdinAndOutput(t *testing.T) {
+	ctx := context.Background()
+	configPath := writeFile(t, "config.json", testConfig)
+	stdin, err := os.Open(writeFile(t, "events", testEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	outputPath := filepath.Join(t.TempDir(), "report.txt")
+
+	var stdout, stderr bytes.Buffer
+	if code := run(ctx, []string{"report", "--config", configPath, "--output", outputPath}, stdin, &stdout, &stderr); code != exitOK {
+		t.Fatalf("run() = %d, want %d, stderr %q", code, exitOK, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("run() output = %q, want nothing on stdout", stdout.String())
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[Finished] 1 [{00:14:58.995, 3.337}] {,} 0/0\n"; string(got) != want {
+		t.Errorf("output file = %q, want %q", got, want)
+	}
+}
+
+func TestRunOutputKeptOnFailure(t *testing.T) {
+	ctx := context.Background()
+	configPath := writeFile(t, "config.json", testConfig)
+	eventsPath := writeFile(t, "events", testEvents)
+	outputPath := writeFile(t, "report.csv", "previous report\n")
+
+	args := []string{"report", "--config", configPath, "--events", eventsPath, "--format", "csv", "--columns", "shoe", "--output", outputPath}
+	if code := run(ctx, args, nil, io.Discard, io.Discard); code != exitUsage {
+		t.Fatalf("run() = %d, want %d", code, exitUsage)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "previous report\n" {
+		t.Errorf("output file = %q, want it untouched", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(outputPath)); len(entries) != 1 {
+		t.Errorf("output directory has %d entries, want the temporary file removed", len(entries))
+	}
+}
+
+func TestRunLiveFollow(t *testing.T) {
+	ctx := context.Background()
+	configPath := writeFile(t, "config.json", testConfig)
+	lines := strings.SplitAfter(testEvents, "\n")
+	eventsPath := writeFile(t, "events", lines[0])
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	output := &syncBuffer{}
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"live", "--config", configPath, "--events", eventsPath, "--follow"}, nil, output, io.Discard)
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(output.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("output = %q, want it to contain %q", output.String(), want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor("The competitor(1) registered\n")
+
+	eventsFile, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventsFile.Close()
+	for _, line := range lines[1:] {
+		if _, err := eventsFile.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor("[10:15:00.000] The competitor(1) has finished\n")
+	if strings.Contains(output.String(), "[Finished] 1") {
+		t.Fatal("live --follow reported before the input ended")
+	}
+
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Fatalf("run() = %d, want %d", code, exitOK)
+	}
+	if !strings.Contains(output.String(), "\n\n[Finished] 1 [{00:14:58.995, 3.337}] {,} 0/0\n") {
+		t.Errorf("output = %q, want the report after the log", output.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to read while run writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
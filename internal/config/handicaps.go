@@ -0,0 +1,174 @@
+package config
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoHandicap is returned for a pursuit competitor who did not finish the previous result
+var ErrNoHandicap = errors.New("no handicap in the previous result")
+
+// finished is the status of a competitor with a total time in a report
+const finished = "Finished"
+
+// LoadHandicaps reads the pursuit handicaps from a previous result file
+func LoadHandicaps(pathToResult string) (map[int]time.Duration, error) {
+	resultFile, err := os.Open(pathToResult)
+	if err != nil {
+		return nil, err
+	}
+	defer resultFile.Close()
+
+	return ParseHandicaps(resultFile)
+}
+
+// ParseHandicaps reads a previous result written by the report command as JSON,
+// CSV or TSV and returns how far behind the fastest finisher every finisher is.
+// Competitors who did not finish get no handicap.
+func ParseHandicaps(r io.Reader) (map[int]time.Duration, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals map[int]time.Duration
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		totals, err = parseJSONResult(trimmed)
+	} else {
+		totals, err = parseTableResult(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("previous result: %w", err)
+	}
+
+	var best time.Duration
+	found := false
+	for _, total := range totals {
+		if !found || total < best {
+			best, found = total, true
+		}
+	}
+	for competitorID, total := range totals {
+		totals[competitorID] = total - best
+	}
+	return totals, nil
+}
+
+// parseJSONResult reads the competitors of a JSON report
+func parseJSONResult(data []byte) (map[int]time.Duration, error) {
+	var result struct {
+		Competitors []struct {
+			ID        int     `json:"id"`
+			Status    string  `json:"status"`
+			TotalTime *string `json:"totalTime"`
+		} `json:"competitors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	totals, seen := make(map[int]time.Duration), make(map[int]bool)
+	for i, competitor := range result.Competitors {
+		totalTime := ""
+		if competitor.TotalTime != nil {
+			totalTime = *competitor.TotalTime
+		}
+		if err := addTotal(totals, seen, competitor.ID, competitor.Status, totalTime); err != nil {
+			return nil, fmt.Errorf("competitors[%d]: %w", i, err)
+		}
+	}
+	return totals, nil
+}
+
+// parseTableResult reads the id, status and total_time columns of a CSV or TSV report
+func parseTableResult(data []byte) (map[int]time.Duration, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.ContainsRune(header, '\t') {
+		reader.Comma = '\t'
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return map[int]time.Duration{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for _, name := range []string{"id", "status", "total_time"} {
+		idx := slices.Index(header, name)
+		if idx < 0 {
+			return nil, fmt.Errorf("no %s column", name)
+		}
+		columns[name] = idx
+	}
+
+	totals, seen := make(map[int]time.Duration), make(map[int]bool)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return totals, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		cell := func(name string) string {
+			if columns[name] >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[columns[name]])
+		}
+		competitorID, err := strconv.Atoi(cell("id"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad competitor %q", line, cell("id"))
+		}
+		if err := addTotal(totals, seen, competitorID, cell("status"), cell("total_time")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// addTotal records the total time of a finisher, a competitor listed twice or a
+// finisher without a total time is an error
+func addTotal(totals map[int]time.Duration, seen map[int]bool, competitorID int, status, totalTime string) error {
+	if competitorID <= 0 {
+		return fmt.Errorf("bad competitor %d", competitorID)
+	}
+	if seen[competitorID] {
+		return fmt.Errorf("competitor %d is listed twice", competitorID)
+	}
+	seen[competitorID] = true
+	if status != finished {
+		return nil
+	}
+	total, err := clock.ParseDuration(totalTime)
+	if err != nil {
+		return fmt.Errorf("competitor %d: total time: %w", competitorID, err)
+	}
+	totals[competitorID] = total
+	return nil
+}
+
+// CheckHandicap tells whether a pursuit competitor has a handicap to start with,
+// other start modes need none
+func (r Race) CheckHandicap(competitorID int) error {
+	if r.StartMode != StartPursuit {
+		return nil
+	}
+	if _, ok := r.Handicaps[competitorID]; !ok {
+		return fmt.Errorf("competitor %d: %w", competitorID, ErrNoHandicap)
+	}
+	return nil
+}
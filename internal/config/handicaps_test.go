@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHandicaps(t *testing.T) {
+	type content struct {
+		name     string
+		input    string
+		expected map[int]time.Duration
+		wantErr  bool
+	}
+
+	tests := []content{
+		{
+			name: "json report",
+			input: `{"schemaVersion": 1, "competitors": [
+				{"id": 1, "place": 1, "status": "Finished", "totalTime": "00:29:40.500"},
+				{"id": 2, "place": 2, "status": "Finished", "totalTime": "00:30:10.000"},
+				{"id": 3, "place": null, "status": "NotFinished", "totalTime": null}
+			]}`,
+			expected: map[int]time.Duration{1: 0, 2: 29500 * time.Millisecond},
+		},
+		{
+			name:     "csv report",
+			input:    "place,id,status,total_time,hits\n1,1,Finished,00:29:40.500,9\n2,2,Finished,00:30:10.000,8\n,3,Disqualified,,0\n",
+			expected: map[int]time.Duration{1: 0, 2: 29500 * time.Millisecond},
+		},
+		{
+			name:     "tsv report",
+			input:    "id\tstatus\ttotal_time\n2\tFinished\t00:31:00.000\n1\tFinished\t00:29:40.500\n",
+			expected: map[int]time.Duration{1: 0, 2: 79500 * time.Millisecond},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: map[int]time.Duration{},
+		},
+		{
+			name:    "missing column",
+			input:   "id,total_time\n1,00:29:40.500\n",
+			wantErr: true,
+		},
+		{
+			name:    "finisher without time",
+			input:   `{"competitors": [{"id": 1, "status": "Finished", "totalTime": null}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "listed twice",
+			input:   "id,status,total_time\n1,Finished,00:29:40.500\n1,NotFinished,\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad competitor",
+			input:   "id,status,total_time\nfirst,Finished,00:29:40.500\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHandicaps(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHandicaps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseHandicaps() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckHandicap(t *testing.T) {
+	pursuit := Race{StartMode: StartPursuit, Handicaps: map[int]time.Duration{1: 0}}
+	if err := pursuit.CheckHandicap(1); err != nil {
+		t.Errorf("CheckHandicap(1) error = %v", err)
+	}
+	if err := pursuit.CheckHandicap(2); !errors.Is(err, ErrNoHandicap) {
+		t.Errorf("CheckHandicap(2) error = %v, want %v", err, ErrNoHandicap)
+	}
+	if err := (Race{StartMode: StartMass}).CheckHandicap(2); err != nil {
+		t.Errorf("CheckHandicap() in a mass start error = %v", err)
+	}
+}
@@ -1,47 +1,180 @@
 package config
 
 import (
-	"CompetitionLogger/pkg/logger"
-	"context"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
 	"encoding/json"
-	"go.uber.org/zap"
 	"io/ioutil"
 	"os"
+	"time"
 )
 
+// DefaultShotsPerLine is the number of shots at the events.DefaultTargets
+// targets of a firing line
+const DefaultShotsPerLine = 5
+
+// Penalty modes: a penalty loop per miss or time added to the result per miss
+const (
+	PenaltyLoop = "loop"
+	PenaltyTime = "time"
+)
+
+// DefaultPenaltyPerMiss is the time added per miss in the time penalty mode
+const DefaultPenaltyPerMiss = time.Minute
+
+// Start modes: individual starts at StartInterval in competitor ID order, a mass
+// start of everyone at Start, or a pursuit delayed by the handicap of each competitor
+const (
+	StartIndividual = "individual"
+	StartMass       = "mass"
+	StartPursuit    = "pursuit"
+)
+
+// Team is a relay team, the members ski the legs in order
+type Team struct {
+	Name    string
+	Members []int
+}
+
+// Shooting positions
+const (
+	Prone    = "prone"
+	Standing = "standing"
+)
+
+// Stage is a firing line visit of the race: the position to shoot in, the lap
+// it comes on and the shots, zero shots meaning Race.Shots
+type Stage struct {
+	Position string
+	Lap      int
+	Shots    int
+}
+
 type Race struct {
-	Laps        int
-	LapLen      int
-	PenaltyLen  int
-	FiringLines int
-	Start       string
-	StartDelta  string
+	Laps           int
+	LapLen         int
+	PenaltyLen     int
+	FiringLines    int
+	ShotsPerLine   int
+	TargetsPerLine int
+	SpareRounds    int
+	Stages         []Stage
+	PenaltyMode    string
+	PenaltyPerMiss string
+	Start          string
+	StartDelta     string
+	StartMode      string
+	StartInterval  string
+	// PreviousResult is the JSON, CSV or TSV report the pursuit handicaps are loaded from
+	PreviousResult string
+	Handicaps      map[int]time.Duration `json:"-"`
+	Teams          []Team
 }
 
-func LoadConfig(ctx context.Context, pathToConfig string) []byte {
-	configFile, err := os.Open(pathToConfig)
-	if err != nil {
-		logger.GetFromContext(ctx).Error("error opening configFile: ", zap.Error(err))
-		return nil
+// Shots is the number of shots per firing line visit, spare rounds not included
+func (r Race) Shots() int {
+	if r.ShotsPerLine > 0 {
+		return r.ShotsPerLine
 	}
-	defer configFile.Close()
+	return DefaultShotsPerLine
+}
 
-	configFileBytes, err := ioutil.ReadAll(configFile)
+// Stage returns the stage of the n-th firing line visit counting from zero,
+// false when the config does not describe it
+func (r Race) Stage(n int) (Stage, bool) {
+	if n < 0 || n >= len(r.Stages) {
+		return Stage{}, false
+	}
+	stage := r.Stages[n]
+	if stage.Shots <= 0 {
+		stage.Shots = r.Shots()
+	}
+	return stage, true
+}
+
+// Targets is the number of targets per firing line
+func (r Race) Targets() int {
+	if r.TargetsPerLine > 0 {
+		return r.TargetsPerLine
+	}
+	return events.DefaultTargets
+}
+
+// TimePenalty tells whether misses add time to the result instead of penalty loops
+func (r Race) TimePenalty() bool {
+	return r.PenaltyMode == PenaltyTime
+}
+
+// PerMiss is the time added per miss in the time penalty mode, an empty or
+// malformed PenaltyPerMiss means DefaultPenaltyPerMiss
+func (r Race) PerMiss() time.Duration {
+	perMiss, err := clock.ParseDuration(r.PenaltyPerMiss)
+	if err != nil || perMiss <= 0 {
+		return DefaultPenaltyPerMiss
+	}
+	return perMiss
+}
+
+// Leg finds the relay team of a competitor and the leg they ski, counting from one
+func (r Race) Leg(competitorID int) (Team, int, bool) {
+	for _, team := range r.Teams {
+		for i, member := range team.Members {
+			if member == competitorID {
+				return team, i + 1, true
+			}
+		}
+	}
+	return Team{}, 0, false
+}
+
+// PlannedStart is the start time the start mode gives a competitor, false when
+// the mode cannot plan it: no valid Start, no StartInterval for individual starts,
+// no handicap for a pursuit or a relay leg that starts at the handover
+func (r Race) PlannedStart(competitorID int) (clock.Time, bool) {
+	if _, leg, ok := r.Leg(competitorID); ok && leg > 1 {
+		return clock.Time{}, false
+	}
+
+	start, err := clock.Parse(r.Start)
 	if err != nil {
-		logger.GetFromContext(ctx).Error("error with convert configFile into []byte: ", zap.Error(err))
-		return nil
+		return clock.Time{}, false
 	}
 
-	return configFileBytes
+	switch r.StartMode {
+	case StartMass:
+		return start, true
+	case StartPursuit:
+		handicap, ok := r.Handicaps[competitorID]
+		if !ok {
+			return clock.Time{}, false
+		}
+		return start.Add(handicap), true
+	default:
+		interval, err := clock.ParseDuration(r.StartInterval)
+		if err != nil || interval <= 0 || competitorID < 1 {
+			return clock.Time{}, false
+		}
+		return start.Add(time.Duration(competitorID-1) * interval), true
+	}
 }
 
-func ParseConfig(ctx context.Context, jsonFileBytes []byte) Race {
-	race := Race{}
-	err := json.Unmarshal(jsonFileBytes, &race)
+// LoadConfig reads the config file
+func LoadConfig(pathToConfig string) ([]byte, error) {
+	configFile, err := os.Open(pathToConfig)
 	if err != nil {
-		logger.GetFromContext(ctx).Error("error unmarshal jsonFileBytes: ", zap.Error(err))
-		return race
+		return nil, err
 	}
+	defer configFile.Close()
+
+	return ioutil.ReadAll(configFile)
+}
 
-	return race
+// ParseConfig decodes and validates the config, a Race is only returned together
+// with every problem found in it
+func ParseConfig(jsonFileBytes []byte) (Race, error) {
+	race := Race{}
+	if err := json.Unmarshal(jsonFileBytes, &race); err != nil {
+		return race, err
+	}
+	return race, race.Validate()
 }
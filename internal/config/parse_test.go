@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"laps": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadConfig(path)
+	if err != nil || string(got) != `{"laps": 2}` {
+		t.Errorf("LoadConfig() = %q, %v, want the file content", got, err)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadConfig() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	valid := `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2, "start": "10:00:00.000", "startDelta": "00:01:30"}`
+
+	type content struct {
+		name     string
+		input    string
+		problems []string
+	}
+
+	tests := []content{
+		{
+			name:  "valid",
+			input: valid,
+		},
+		{
+			name:     "malformed json",
+			input:    `{"laps": 2`,
+			problems: []string{"unexpected end of JSON input"},
+		},
+		{
+			name:  "empty",
+			input: `{}`,
+			problems: []string{
+				"laps must be positive, got 0",
+				"lapLen must be positive, got 0",
+				"penaltyLen must be positive, got 0",
+				`start "": `,
+				`startDelta "": `,
+			},
+		},
+		{
+			name:  "every problem at once",
+			input: `{"laps": 1, "lapLen": -1, "penaltyLen": 150, "firingLines": 2, "start": "25:00:00", "startDelta": "soon"}`,
+			problems: []string{
+				"lapLen must be positive, got -1",
+				"firingLines must be between 0 and laps (1), got 2",
+				`start "25:00:00": `,
+				`startDelta "soon": `,
+			},
+		},
+		{
+			name: "modes",
+			input: `{"laps": 2, "lapLen": 3500, "firingLines": 2, "start": "10:00:00", "startDelta": "00:01:00",
+				"startMode": "wave", "startInterval": "00:00:00", "penaltyMode": "time", "penaltyPerMiss": "a minute"}`,
+			problems: []string{
+				`startMode must be individual, mass or pursuit, got "wave"`,
+				"startInterval must be positive, got 00:00:00",
+				`penaltyPerMiss "a minute": `,
+			},
+		},
+		{
+			name:     "pursuit without previous result",
+			input:    `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00", "startDelta": "00:01:00", "startMode": "pursuit"}`,
+			problems: []string{"the pursuit start mode needs previousResult"},
+		},
+		{
+			name: "stages and teams",
+			input: `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00", "startDelta": "00:01:00",
+				"stages": [{"position": "prone", "lap": 1}, {"position": "kneeling", "lap": 3, "shots": -1}],
+				"teams": [{"name": "Blue", "members": [1, 2]}, {"name": "", "members": [2]}]}`,
+			problems: []string{
+				`stages[1]: position must be prone or standing, got "kneeling"`,
+				"stages[1]: lap must be between 1 and laps (2), got 3",
+				"stages[1]: shots must not be negative, got -1",
+				"teams[1]: name is empty",
+				"teams[1]: competitor 2 skis more than one leg",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.input))
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("ParseConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ParseConfig() error = nil, want %q", tt.problems)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if errors.Is(err, ErrInvalid) {
+				if lines[0] != ErrInvalid.Error()+":" {
+					t.Errorf("ParseConfig() error = %v, want a single %q heading", err, ErrInvalid)
+				}
+				lines = lines[1:]
+			}
+			if len(lines) != len(tt.problems) {
+				t.Errorf("ParseConfig() error has %d problems, want %d: %v", len(lines), len(tt.problems), err)
+			}
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("ParseConfig() error = %v, want it to contain %q", err, problem)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateErrInvalid(t *testing.T) {
+	err := Race{}.Validate()
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalid)
+	}
+}
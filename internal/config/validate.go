@@ -0,0 +1,113 @@
+package config
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrInvalid is wrapped by the problems Validate finds
+var ErrInvalid = errors.New("invalid config")
+
+// Validate checks every field of the race and reports all problems at once
+func (r Race) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if r.Laps <= 0 {
+		fail("laps must be positive, got %d", r.Laps)
+	}
+	if r.LapLen <= 0 {
+		fail("lapLen must be positive, got %d", r.LapLen)
+	}
+	if r.PenaltyLen <= 0 && !r.TimePenalty() {
+		fail("penaltyLen must be positive, got %d", r.PenaltyLen)
+	}
+	if r.FiringLines < 0 || r.FiringLines > r.Laps {
+		fail("firingLines must be between 0 and laps (%d), got %d", r.Laps, r.FiringLines)
+	}
+	if r.ShotsPerLine < 0 {
+		fail("shotsPerLine must not be negative, got %d", r.ShotsPerLine)
+	}
+	if r.TargetsPerLine < 0 {
+		fail("targetsPerLine must not be negative, got %d", r.TargetsPerLine)
+	}
+	if r.SpareRounds < 0 {
+		fail("spareRounds must not be negative, got %d", r.SpareRounds)
+	}
+
+	if _, err := clock.Parse(r.Start); err != nil {
+		fail("start %q: %v", r.Start, err)
+	}
+	if delta, err := clock.ParseDuration(r.StartDelta); err != nil {
+		fail("startDelta %q: %v", r.StartDelta, err)
+	} else if delta < 0 {
+		fail("startDelta must not be negative, got %s", r.StartDelta)
+	}
+
+	switch r.StartMode {
+	case "", StartIndividual, StartMass:
+	case StartPursuit:
+		if r.PreviousResult == "" && r.Handicaps == nil {
+			fail("the pursuit start mode needs previousResult")
+		}
+	default:
+		fail("startMode must be %s, %s or %s, got %q", StartIndividual, StartMass, StartPursuit, r.StartMode)
+	}
+	if r.StartInterval != "" {
+		if interval, err := clock.ParseDuration(r.StartInterval); err != nil {
+			fail("startInterval %q: %v", r.StartInterval, err)
+		} else if interval <= 0 {
+			fail("startInterval must be positive, got %s", r.StartInterval)
+		}
+	}
+
+	switch r.PenaltyMode {
+	case "", PenaltyLoop, PenaltyTime:
+	default:
+		fail("penaltyMode must be %s or %s, got %q", PenaltyLoop, PenaltyTime, r.PenaltyMode)
+	}
+	if r.PenaltyPerMiss != "" {
+		if perMiss, err := clock.ParseDuration(r.PenaltyPerMiss); err != nil {
+			fail("penaltyPerMiss %q: %v", r.PenaltyPerMiss, err)
+		} else if perMiss <= 0 {
+			fail("penaltyPerMiss must be positive, got %s", r.PenaltyPerMiss)
+		}
+	}
+
+	for i, stage := range r.Stages {
+		if stage.Position != Prone && stage.Position != Standing {
+			fail("stages[%d]: position must be %s or %s, got %q", i, Prone, Standing, stage.Position)
+		}
+		if stage.Lap < 1 || stage.Lap > r.Laps {
+			fail("stages[%d]: lap must be between 1 and laps (%d), got %d", i, r.Laps, stage.Lap)
+		}
+		if stage.Shots < 0 {
+			fail("stages[%d]: shots must not be negative, got %d", i, stage.Shots)
+		}
+	}
+
+	var members []int
+	for i, team := range r.Teams {
+		if team.Name == "" {
+			fail("teams[%d]: name is empty", i)
+		}
+		if len(team.Members) == 0 {
+			fail("teams[%d]: no members", i)
+		}
+		for _, member := range team.Members {
+			if slices.Contains(members, member) {
+				fail("teams[%d]: competitor %d skis more than one leg", i, member)
+			}
+			members = append(members, member)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n%w", ErrInvalid, errors.Join(errs...))
+}
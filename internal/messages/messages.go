@@ -0,0 +1,183 @@
+package messages
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultLocale is built in, the other locales are loaded from files
+const DefaultLocale = "en"
+
+var ErrUnknownKey = errors.New("unknown message key")
+
+// ErrBadFormat is returned for a message whose verbs do not fit the arguments of its key
+var ErrBadFormat = errors.New("message does not fit its arguments")
+
+// english is the default catalogue. Event messages get the time, the competitor
+// and the extra param of the event, in that order, so translations refer to
+// them by index like %[2]s.
+var english = map[string]string{
+	"event.1":       "[%[1]s] The competitor(%[2]s) registered",
+	"event.2":       "[%[1]s] The start time for the competitor(%[2]s) was set by a draw to %[3]s",
+	"event.3":       "[%[1]s] The competitor(%[2]s) is on the start line",
+	"event.4":       "[%[1]s] The competitor(%[2]s) has started",
+	"event.5":       "[%[1]s] The competitor(%[2]s) is on the firing range(%[3]d)",
+	"event.6":       "[%[1]s] The target(%[3]d) has been hit by competitor(%[2]s)",
+	"event.7":       "[%[1]s] The competitor(%[2]s) left the firing range",
+	"event.8":       "[%[1]s] The competitor(%[2]s) entered the penalty laps",
+	"event.9":       "[%[1]s] The competitor(%[2]s) left the penalty laps",
+	"event.10":      "[%[1]s] The competitor(%[2]s) ended the main lap",
+	"event.11":      "[%[1]s] The competitor(%[2]s) can't continue: %[3]s",
+	"event.12":      "[%[1]s] The competitor(%[2]s) handed over to the next leg",
+	"event.32":      "[%[1]s] The competitor(%[2]s) is disqualified",
+	"event.33":      "[%[1]s] The competitor(%[2]s) has finished",
+	"event.unknown": "Unknown event %[3]d for competitor %[2]s",
+
+	"status.Finished":    "Finished",
+	"status.Started":     "Started",
+	"status.NotFinished": "NotFinished",
+	"status.NotStarted":  "NotStarted",
+
+	"report.overall":      "Overall",
+	"report.disqualified": "disqualified at %s: %s",
+	"report.inconsistent": "inconsistent: %d violations",
+
+	"html.title":      "Results",
+	"html.place":      "Place",
+	"html.bib":        "Bib",
+	"html.name":       "Name",
+	"html.club":       "Club",
+	"html.status":     "Status",
+	"html.time":       "Time",
+	"html.lap":        "Lap %d",
+	"html.range":      "Range %d",
+	"html.hits":       "Hits",
+	"html.penalty":    "Penalty",
+	"html.competitor": "Competitor %d",
+	"html.relay":      "Relay",
+	"html.team":       "Team",
+	"html.legs":       "Legs",
+}
+
+// samples are arguments of the types the messages with any are formatted with,
+// event messages not listed get the time and the competitor
+var samples = map[string][]any{
+	"event.2":             {clock.Time{}, "", clock.Time{}},
+	"event.5":             {clock.Time{}, "", 0},
+	"event.6":             {clock.Time{}, "", 0},
+	"event.11":            {clock.Time{}, "", ""},
+	"event.unknown":       {clock.Time{}, "", 0},
+	"report.disqualified": {clock.Time{}, ""},
+	"report.inconsistent": {0},
+	"html.lap":            {0},
+	"html.range":          {0},
+	"html.competitor":     {0},
+}
+
+// sample is the arguments of the types key is formatted with
+func sample(key string) []any {
+	if args, ok := samples[key]; ok {
+		return args
+	}
+	if strings.HasPrefix(key, "event.") {
+		return []any{clock.Time{}, ""}
+	}
+	return nil
+}
+
+// Catalog holds the messages of a locale, keys it lacks fall back to English.
+// A nil Catalog is the English one.
+type Catalog struct {
+	locale   string
+	messages map[string]string
+}
+
+// New checks that every key of messages is a known one and that its message
+// formats the arguments English gets without a %! error
+func New(locale string, messages map[string]string) (*Catalog, error) {
+	var errs []error
+	for key, message := range messages {
+		if _, ok := english[key]; !ok {
+			errs = append(errs, fmt.Errorf("%w %q", ErrUnknownKey, key))
+			continue
+		}
+		if formatted := fmt.Sprintf(message, sample(key)...); strings.Contains(formatted, "%!") {
+			errs = append(errs, fmt.Errorf("%w: %s formats as %q", ErrBadFormat, key, formatted))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Catalog{locale: locale, messages: messages}, nil
+}
+
+// Load reads a catalogue file, a JSON object of messages by key. The locale is
+// the file name without the extension.
+func Load(ctx context.Context, pathToCatalog string) (*Catalog, error) {
+	catalogFile, err := os.Open(pathToCatalog)
+	if err != nil {
+		return nil, err
+	}
+	defer catalogFile.Close()
+
+	locale := strings.TrimSuffix(filepath.Base(pathToCatalog), filepath.Ext(pathToCatalog))
+	catalog, err := Parse(locale, catalogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.GetFromContext(ctx).Info("success loading messages", zap.String("locale", locale), zap.Int("messages", len(catalog.messages)))
+	return catalog, nil
+}
+
+// Parse reads the JSON object of messages of locale
+func Parse(locale string, r io.Reader) (*Catalog, error) {
+	var messages map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("messages: %w", err)
+	}
+	return New(locale, messages)
+}
+
+// Locale of the catalogue
+func (c *Catalog) Locale() string {
+	if c == nil || c.locale == "" {
+		return DefaultLocale
+	}
+	return c.locale
+}
+
+// Text is the message of key, the key itself when no catalogue has it
+func (c *Catalog) Text(key string) string {
+	if c != nil {
+		if message, ok := c.messages[key]; ok {
+			return message
+		}
+	}
+	if message, ok := english[key]; ok {
+		return message
+	}
+	return key
+}
+
+// Sprintf formats the message of key with args
+func (c *Catalog) Sprintf(key string, args ...any) string {
+	return fmt.Sprintf(c.Text(key), args...)
+}
+
+// Status is the label of a competitor or team status, unknown ones are kept
+func (c *Catalog) Status(status string) string {
+	if _, ok := english["status."+status]; !ok {
+		return status
+	}
+	return c.Text("status." + status)
+}
@@ -0,0 +1,153 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type content struct {
+		name    string
+		input   string
+		wantErr bool
+		errIs   error
+	}
+
+	tests := []content{
+		{
+			name:  "partial",
+			input: `{"status.Finished": "Im Ziel"}`,
+		},
+		{
+			name:    "unknown key",
+			input:   `{"status.Finished": "Im Ziel", "status.Lost": "Verirrt"}`,
+			wantErr: true,
+			errIs:   ErrUnknownKey,
+		},
+		{
+			name:    "verb of the wrong type",
+			input:   `{"html.lap": "Runde %s"}`,
+			wantErr: true,
+			errIs:   ErrBadFormat,
+		},
+		{
+			name:    "missing argument",
+			input:   `{"event.5": "[%s] Teilnehmer(%s) am Schiessstand %d, Bahn %d"}`,
+			wantErr: true,
+			errIs:   ErrBadFormat,
+		},
+		{
+			name:    "extra argument",
+			input:   `{"event.6": "[%s] Scheibe getroffen"}`,
+			wantErr: true,
+			errIs:   ErrBadFormat,
+		},
+		{
+			name:  "reordered arguments",
+			input: `{"event.6": "[%[1]s] Scheibe(%[3]d) getroffen"}`,
+		},
+		{
+			name:    "not an object",
+			input:   `["Im Ziel"]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, err := Parse("de", strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.errIs)
+			}
+			if err == nil && catalog.Locale() != "de" {
+				t.Errorf("Locale() = %q, want %q", catalog.Locale(), "de")
+			}
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog, err := New("de", map[string]string{"status.Finished": "Im Ziel", "html.lap": "Runde %d"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		catalog *Catalog
+		got     string
+		want    string
+	}{
+		{name: "translated", got: catalog.Status("Finished"), want: "Im Ziel"},
+		{name: "english fallback", got: catalog.Status("NotStarted"), want: "NotStarted"},
+		{name: "unknown status", got: catalog.Status("Lost"), want: "Lost"},
+		{name: "sprintf", got: catalog.Sprintf("html.lap", 2), want: "Runde 2"},
+		{name: "unknown key", got: catalog.Text("html.missing"), want: "html.missing"},
+		{name: "nil catalog", got: (*Catalog)(nil).Sprintf("event.6", "10:00:00.000", "1", 4), want: "[10:00:00.000] The target(4) has been hit by competitor(1)"},
+		{name: "nil locale", got: (*Catalog)(nil).Locale(), want: DefaultLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnglishSamples(t *testing.T) {
+	for key, message := range english {
+		if formatted := fmt.Sprintf(message, sample(key)...); strings.Contains(formatted, "%!") {
+			t.Errorf("%s formats as %q", key, formatted)
+		}
+	}
+}
+
+// TestLocales checks that the shipped catalogues translate every message with
+// the same arguments as English
+func TestLocales(t *testing.T) {
+	ctx := context.Background()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "locales", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no locales found: %v", err)
+	}
+
+	verbs := regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			catalog, err := Load(ctx, path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			for key, message := range english {
+				translated, ok := catalog.messages[key]
+				if !ok {
+					t.Errorf("%s is not translated", key)
+					continue
+				}
+				want := verbs.FindAllString(message, -1)
+				got := verbs.FindAllString(translated, -1)
+				slices.Sort(want)
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Errorf("%s takes %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := Load(ctx, filepath.Join(t.TempDir(), "xx.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a missing file error = %v, want %v", err, os.ErrNotExist)
+	}
+}
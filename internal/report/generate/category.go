@@ -0,0 +1,63 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CategoryTable is the ranked table of the competitors sharing a category
+type CategoryTable struct {
+	Category string
+	Reports  []worker.CompetitorReport
+}
+
+// SplitByCategory groups copies of the reports by a roster attribute such as
+// roster.ByCategory and ranks every group on its own. Tables come in category
+// name order; competitors without the attribute only appear in the overall table.
+func SplitByCategory(reports []worker.CompetitorReport, competitors *roster.Roster, attribute string) ([]CategoryTable, error) {
+	if _, ok := (roster.Competitor{}).Attribute(attribute); !ok {
+		return nil, fmt.Errorf("unknown competitor attribute %q", attribute)
+	}
+
+	groups := make(map[string][]worker.CompetitorReport)
+	for _, report := range reports {
+		competitor, ok := competitors.Get(report.CompetitorID)
+		if !ok {
+			continue
+		}
+		if category, _ := competitor.Attribute(attribute); category != "" {
+			groups[category] = append(groups[category], report)
+		}
+	}
+
+	var tables []CategoryTable
+	for category, group := range groups {
+		worker.Rank(group)
+		tables = append(tables, CategoryTable{Category: category, Reports: group})
+	}
+	slices.SortFunc(tables, func(a, b CategoryTable) int {
+		return strings.Compare(a.Category, b.Category)
+	})
+	return tables, nil
+}
+
+// FormatCategories renders the overall table followed by a table per value of
+// the roster attribute, each under its own heading
+func FormatCategories(reports []worker.CompetitorReport, attribute string, opts Options) (string, error) {
+	tables, err := SplitByCategory(reports, opts.Competitors, attribute)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(opts.Messages.Text("report.overall") + "\n")
+	result.WriteString(FormatReport(slices.Clone(reports), opts))
+	for _, table := range tables {
+		result.WriteString(fmt.Sprintf("\n%s\n", table.Category))
+		result.WriteString(FormatReport(table.Reports, opts))
+	}
+	return result.String(), nil
+}
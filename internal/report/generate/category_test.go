@@ -0,0 +1,163 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"testing"
+)
+
+func TestSplitByCategory(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Name: "Anna Berg", Gender: "F", Category: "Senior"},
+		{ID: 2, Name: "Ole Dahl", Gender: "M", Category: "Senior"},
+		{ID: 3, Name: "Mia Koskinen", Gender: "F", Category: "Junior"},
+		{ID: 4, Name: "Jonas Weber", Gender: "M"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:31:00.000")},
+		{CompetitorID: 2, Status: "Finished", TotalTime: clock.MustParseDuration("00:29:00.000")},
+		{CompetitorID: 3, Status: "Finished", TotalTime: clock.MustParseDuration("00:30:00.000")},
+		{CompetitorID: 4, Status: "Finished", TotalTime: clock.MustParseDuration("00:28:00.000")},
+		{CompetitorID: 5, Status: "Finished", TotalTime: clock.MustParseDuration("00:27:00.000")},
+	}
+
+	type entry struct {
+		competitorID int
+		place        int
+	}
+	type content struct {
+		name      string
+		attribute string
+		expected  map[string][]entry
+		order     []string
+		wantErr   bool
+	}
+
+	tests := []content{
+		{
+			name:      "by category",
+			attribute: roster.ByCategory,
+			expected: map[string][]entry{
+				"Junior": {{3, 1}},
+				"Senior": {{2, 1}, {1, 2}},
+			},
+			order: []string{"Junior", "Senior"},
+		},
+		{
+			name:      "by gender",
+			attribute: roster.ByGender,
+			expected: map[string][]entry{
+				"F": {{3, 1}, {1, 2}},
+				"M": {{4, 1}, {2, 2}},
+			},
+			order: []string{"F", "M"},
+		},
+		{
+			name:      "unknown attribute",
+			attribute: "shoe size",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := SplitByCategory(reports, competitors, tt.attribute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitByCategory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(tables) != len(tt.order) {
+				t.Fatalf("SplitByCategory() = %d tables, want %d", len(tables), len(tt.order))
+			}
+			for i, table := range tables {
+				if table.Category != tt.order[i] {
+					t.Errorf("tables[%d].Category = %q, want %q", i, table.Category, tt.order[i])
+				}
+				want := tt.expected[table.Category]
+				if len(table.Reports) != len(want) {
+					t.Fatalf("%s = %d competitors, want %d", table.Category, len(table.Reports), len(want))
+				}
+				for j, report := range table.Reports {
+					if report.CompetitorID != want[j].competitorID || report.Place != want[j].place {
+						t.Errorf("%s[%d] = competitor %d place %d, want competitor %d place %d",
+							table.Category, j, report.CompetitorID, report.Place, want[j].competitorID, want[j].place)
+					}
+				}
+			}
+		})
+	}
+
+	for _, report := range reports {
+		if report.Place != 0 {
+			t.Errorf("SplitByCategory() changed the place of competitor %d to %d", report.CompetitorID, report.Place)
+		}
+	}
+}
+
+func TestFormatCategories(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Name: "Anna Berg", Category: "Senior"},
+		{ID: 2, Name: "Mia Koskinen", Category: "Junior"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:29:00.000")},
+		{CompetitorID: 2, Status: "NotStarted"},
+	}
+
+	want := `Overall
+[Finished] 1 Anna Berg [] {,} 0/0
+[NotStarted] 2 Mia Koskinen [] {,} 0/0
+
+Junior
+[NotStarted] 2 Mia Koskinen [] {,} 0/0
+
+Senior
+[Finished] 1 Anna Berg [] {,} 0/0
+`
+	got, err := FormatCategories(reports, roster.ByCategory, Options{Competitors: competitors})
+	if err != nil {
+		t.Fatalf("FormatCategories() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("FormatCategories() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatCategoriesTranslated(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{{ID: 1, Name: "Anna Berg", Category: "Senior"}})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	catalog, err := messages.New("de", map[string]string{
+		"status.NotFinished":  "Nicht im Ziel",
+		"report.overall":      "Gesamtwertung",
+		"report.disqualified": "disqualifiziert um %s: %s",
+	})
+	if err != nil {
+		t.Fatalf("messages.New() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1, Status: "NotFinished", DisqualifiedAt: clock.MustParse("10:05:00.000"), Reason: "Fehlstart"},
+	}
+
+	want := `Gesamtwertung
+[Nicht im Ziel] 1 Anna Berg [] {,} 0/0 (disqualifiziert um 10:05:00.000: Fehlstart)
+
+Senior
+[Nicht im Ziel] 1 Anna Berg [] {,} 0/0 (disqualifiziert um 10:05:00.000: Fehlstart)
+`
+	got, err := FormatCategories(reports, roster.ByCategory, Options{Competitors: competitors, Messages: catalog})
+	if err != nil {
+		t.Fatalf("FormatCategories() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("FormatCategories() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column groups of the tabular export that expand to a column per lap, per
+// penalty loop visit and per firing line visit
+const (
+	LapColumns      = "laps"
+	PenaltyColumns  = "penalties"
+	ShootingColumns = "shooting"
+)
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+// TableOptions tune EncodeTable
+type TableOptions struct {
+	// Comma separates the fields, zero means ','
+	Comma rune
+	// Columns selects the columns in order by name or group, empty means all
+	Columns []string
+}
+
+// tableColumn is a column of the tabular export
+type tableColumn struct {
+	name  string
+	value func(r worker.CompetitorReport, competitor roster.Competitor) string
+}
+
+// EncodeTable writes a header and a row per ranked competitor as RFC 4180 CSV,
+// or TSV with Comma '\t'. There is a column per lap, penalty loop visit and
+// firing line visit of the longest race in the reports.
+func EncodeTable(w io.Writer, reports []worker.CompetitorReport, competitors *roster.Roster, opts TableOptions) error {
+	worker.Rank(reports)
+
+	columns, err := selectColumns(tableColumns(reports), opts.Columns)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		competitor, _ := competitors.Get(r.CompetitorID)
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(r, competitor)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// tableColumns lists every column in the default order: the fixed ones, then a
+// pair per lap, per penalty loop visit and per firing line visit
+func tableColumns(reports []worker.CompetitorReport) []tableColumn {
+	laps, penalties, visits := 0, 0, 0
+	for _, r := range reports {
+		laps = max(laps, len(r.Laps))
+		penalties = max(penalties, len(r.Penalties))
+		visits = max(visits, len(r.Shooting))
+	}
+
+	columns := []tableColumn{
+		{"place", func(r worker.CompetitorReport, _ roster.Competitor) string { return number(r.Place) }},
+		{"id", func(r worker.CompetitorReport, _ roster.Competitor) string { return strconv.Itoa(r.CompetitorID) }},
+		{"bib", func(_ worker.CompetitorReport, c roster.Competitor) string { return number(c.Bib) }},
+		{"name", func(_ worker.CompetitorReport, c roster.Competitor) string { return c.Name }},
+		{"club", func(_ worker.CompetitorReport, c roster.Competitor) string { return c.Club }},
+		{"gender", func(_ worker.CompetitorReport, c roster.Competitor) string { return c.Gender }},
+		{"category", func(_ worker.CompetitorReport, c roster.Competitor) string { return c.Category }},
+		{"status", func(r worker.CompetitorReport, _ roster.Competitor) string { return r.Status }},
+		{"total_time", func(r worker.CompetitorReport, _ roster.Competitor) string { return cellDuration(r.TotalTime) }},
+		{"hits", func(r worker.CompetitorReport, _ roster.Competitor) string { return strconv.Itoa(r.Hits()) }},
+		{"shots", func(r worker.CompetitorReport, _ roster.Competitor) string { return strconv.Itoa(r.Shots()) }},
+		{"penalty_time", func(r worker.CompetitorReport, _ roster.Competitor) string { return cellDuration(r.Penalty.Time) }},
+		{"penalty_speed", func(r worker.CompetitorReport, _ roster.Competitor) string {
+			return cellSpeed(r.Penalty.Time, r.Penalty.Speed)
+		}},
+		{"added_time", func(r worker.CompetitorReport, _ roster.Competitor) string {
+			if !r.TimePenalty {
+				return ""
+			}
+			return clock.FormatDuration(r.AddedTime)
+		}},
+		{"reason", func(r worker.CompetitorReport, _ roster.Competitor) string { return r.Reason }},
+	}
+
+	for i := range laps {
+		columns = append(columns,
+			tableColumn{fmt.Sprintf("lap%d_time", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Laps) {
+					return ""
+				}
+				return cellDuration(r.Laps[i].Time)
+			}},
+			tableColumn{fmt.Sprintf("lap%d_speed", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Laps) {
+					return ""
+				}
+				return cellSpeed(r.Laps[i].Time, r.Laps[i].Speed)
+			}},
+		)
+	}
+
+	for i := range penalties {
+		columns = append(columns,
+			tableColumn{fmt.Sprintf("penalty%d_misses", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Penalties) {
+					return ""
+				}
+				return strconv.Itoa(r.Penalties[i].Misses)
+			}},
+			tableColumn{fmt.Sprintf("penalty%d_time", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Penalties) {
+					return ""
+				}
+				return cellDuration(r.Penalties[i].Time)
+			}},
+		)
+	}
+
+	for i := range visits {
+		columns = append(columns,
+			tableColumn{fmt.Sprintf("range%d_hits", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Shooting) {
+					return ""
+				}
+				return fmt.Sprintf("%d/%d", len(r.Shooting[i].Hits), r.Shooting[i].Shots)
+			}},
+			tableColumn{fmt.Sprintf("range%d_time", i+1), func(r worker.CompetitorReport, _ roster.Competitor) string {
+				if i >= len(r.Shooting) {
+					return ""
+				}
+				return cellDuration(r.Shooting[i].Time)
+			}},
+		)
+	}
+
+	return columns
+}
+
+// selectColumns picks columns by name, a group name selects all its columns
+func selectColumns(all []tableColumn, names []string) ([]tableColumn, error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	prefixes := map[string]string{LapColumns: "lap", PenaltyColumns: "penalty", ShootingColumns: "range"}
+	var result []tableColumn
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, column := range all {
+			if column.name == name || isGroupColumn(column.name, prefixes[name]) {
+				result = append(result, column)
+				found = true
+			}
+		}
+		_, isGroup := prefixes[name]
+		if !found && !isGroup {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+	}
+	return result, nil
+}
+
+// isGroupColumn tells whether a column is numbered within a group, like lap2_time for "lap"
+func isGroupColumn(name, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	rest := name[len(prefix):]
+	return rest != "" && rest[0] >= '0' && rest[0] <= '9'
+}
+
+// number leaves zero cells empty
+func number(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func cellDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return clock.FormatDuration(d)
+}
+
+func cellSpeed(d time.Duration, speed float64) string {
+	if d == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", speed)
+}
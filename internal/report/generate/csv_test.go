@@ -0,0 +1,84 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+)
+
+func TestEncodeTable(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:30"}
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Bib: 11, Name: "Berg, Anna", Club: "NOR", Gender: "F", Category: "Senior"},
+		{ID: 3, Bib: 13, Name: `Mia "Kossu" Koskinen`, Club: "FIN", Gender: "F", Category: "Junior"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+
+	type content struct {
+		name   string
+		opts   TableOptions
+		golden string
+	}
+
+	tests := []content{
+		{
+			name:   "csv",
+			golden: "report.golden.csv",
+		},
+		{
+			name:   "tsv",
+			opts:   TableOptions{Comma: '\t'},
+			golden: "report.golden.tsv",
+		},
+		{
+			name:   "selected columns",
+			opts:   TableOptions{Columns: []string{"place", "name", "total_time", "laps", "range2_hits", "reason"}},
+			golden: "report_columns.golden.csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := EncodeTable(&got, raceReports(t, raceConfig), competitors, tt.opts); err != nil {
+				t.Fatalf("EncodeTable() error = %v", err)
+			}
+
+			reader := csv.NewReader(bytes.NewReader(got.Bytes()))
+			if tt.opts.Comma != 0 {
+				reader.Comma = tt.opts.Comma
+			}
+			if _, err := reader.ReadAll(); err != nil {
+				t.Fatalf("EncodeTable() output does not read back: %v", err)
+			}
+			golden(t, tt.golden, got.Bytes())
+		})
+	}
+}
+
+func TestEncodeTableColumns(t *testing.T) {
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1, Status: "Finished", Laps: []worker.LapInfo{{}, {}, {}}},
+		{CompetitorID: 2, Status: "NotStarted", Laps: []worker.LapInfo{{}}},
+	}
+
+	var got bytes.Buffer
+	if err := EncodeTable(&got, reports, nil, TableOptions{Columns: []string{"id", "laps", "shooting"}}); err != nil {
+		t.Fatalf("EncodeTable() error = %v", err)
+	}
+	want := "id,lap1_time,lap1_speed,lap2_time,lap2_speed,lap3_time,lap3_speed\n1,,,,,,\n2,,,,,,\n"
+	if got.String() != want {
+		t.Errorf("EncodeTable() = %q, want %q", got.String(), want)
+	}
+
+	err := EncodeTable(&got, reports, nil, TableOptions{Columns: []string{"id", "lap9_time"}})
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("EncodeTable() error = %v, want %v", err, ErrUnknownColumn)
+	}
+}
@@ -0,0 +1,106 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/pkg/events"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"strconv"
+)
+
+// Sources of the events in the machine-readable log
+const (
+	SourceIncoming  = "incoming"
+	SourceGenerated = "generated"
+)
+
+// logRecord is an event of the machine-readable log, params absent from the
+// event are left out
+type logRecord struct {
+	Time         string `json:"time"`
+	EventID      int    `json:"eventId"`
+	Event        string `json:"event"`
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name,omitempty"`
+	StartTime    string `json:"startTime,omitempty"`
+	FiringRange  int    `json:"firingRange,omitempty"`
+	Target       int    `json:"target,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+	Source       string `json:"source"`
+	Message      string `json:"message"`
+}
+
+var logColumns = []string{"time", "event_id", "event", "competitor_id", "name", "start_time", "firing_range", "target", "comment", "source", "message"}
+
+func newLogRecord(event events.Event, competitors *roster.Roster) logRecord {
+	record := logRecord{
+		Time:         event.Time.String(),
+		EventID:      event.EventID,
+		Event:        events.Name(event.EventID),
+		CompetitorID: event.CompetitorID,
+		FiringRange:  event.FiringRange,
+		Target:       event.Target,
+		Comment:      event.Comment,
+		Source:       SourceGenerated,
+		Message:      Log(event, Options{Competitors: competitors}),
+	}
+	if competitor, ok := competitors.Get(event.CompetitorID); ok {
+		record.Name = competitor.Name
+	}
+	if !event.StartTime.IsZero() {
+		record.StartTime = event.StartTime.String()
+	}
+	if events.Incoming(event.EventID) {
+		record.Source = SourceIncoming
+	}
+	return record
+}
+
+// EncodeLogJSONLines writes every event of the chronological stream as a JSON
+// object on its own line
+func EncodeLogJSONLines(w io.Writer, all iter.Seq[events.Event], competitors *roster.Roster) error {
+	encoder := json.NewEncoder(w)
+	for event := range all {
+		if err := encoder.Encode(newLogRecord(event, competitors)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeLogTable writes a header and a row per event of the chronological
+// stream as RFC 4180 CSV, comma zero means ','
+func EncodeLogTable(w io.Writer, all iter.Seq[events.Event], competitors *roster.Roster, comma rune) error {
+	writer := csv.NewWriter(w)
+	if comma != 0 {
+		writer.Comma = comma
+	}
+	if err := writer.Write(logColumns); err != nil {
+		return err
+	}
+
+	for event := range all {
+		record := newLogRecord(event, competitors)
+		row := []string{
+			record.Time,
+			strconv.Itoa(record.EventID),
+			record.Event,
+			strconv.Itoa(record.CompetitorID),
+			record.Name,
+			record.StartTime,
+			number(record.FiringRange),
+			number(record.Target),
+			record.Comment,
+			record.Source,
+			record.Message,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,69 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func logEvents() []events.Event {
+	return []events.Event{
+		{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+		{Time: clock.MustParse("09:49:31.659"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 2, Comment: `Lost in the "forest", again`},
+		{Time: clock.MustParse("10:25:26.047"), EventID: 33, CompetitorID: 1},
+	}
+}
+
+func TestEncodeLogJSONLines(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{{ID: 1, Name: "Anna Berg"}})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := EncodeLogJSONLines(&got, slices.Values(logEvents()), competitors); err != nil {
+		t.Fatalf("EncodeLogJSONLines() error = %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(bytes.NewReader(got.Bytes()))
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			t.Errorf("line %q is not valid JSON", scanner.Text())
+		}
+		lines++
+	}
+	if lines != len(logEvents()) {
+		t.Errorf("EncodeLogJSONLines() wrote %d lines, want %d", lines, len(logEvents()))
+	}
+	golden(t, "log.golden.jsonl", got.Bytes())
+}
+
+func TestEncodeLogTable(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{{ID: 1, Name: "Anna Berg"}})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := EncodeLogTable(&got, slices.Values(logEvents()), competitors, 0); err != nil {
+		t.Fatalf("EncodeLogTable() error = %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(got.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("EncodeLogTable() output does not read back: %v", err)
+	}
+	if len(rows) != len(logEvents())+1 {
+		t.Errorf("EncodeLogTable() wrote %d rows, want a header and %d events", len(rows), len(logEvents()))
+	}
+	golden(t, "log.golden.csv", got.Bytes())
+}
@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HTMLPageTemplate is the template RenderHTML executes, an override file may
+// redefine it or any of the templates it is built from. The templates and the
+// data they get are documented in docs/report-html.md.
+const HTMLPageTemplate = "page"
+
+//go:embed templates/report.html.tmpl
+var defaultHTML string
+
+// HTMLOptions tune RenderHTML
+type HTMLOptions struct {
+	Options
+	// CategoryBy adds a section per value of the roster attribute after the overall one
+	CategoryBy string
+	// Template replaces the default templates, see ParseHTMLTemplate
+	Template *template.Template
+}
+
+// HTMLPage is the data of the page template
+type HTMLPage struct {
+	Sections []HTMLSection
+	Teams    []worker.TeamReport
+}
+
+// HTMLSection is a ranked table, Name is empty when the results are not split.
+// Laps and FiringLines are the number of split and shooting columns, the same
+// in every section of a page.
+type HTMLSection struct {
+	Name        string
+	Laps        int
+	FiringLines int
+	Rows        []HTMLRow
+}
+
+// HTMLRow is a competitor with the cells padded to the columns of the page
+type HTMLRow struct {
+	worker.CompetitorReport
+	Competitor roster.Competitor
+	Splits     []worker.LapInfo
+	Ranges     []HTMLRange
+}
+
+// HTMLRange is a firing line visit, a Dot per target tells whether it was hit
+type HTMLRange struct {
+	worker.FiringVisit
+	Visited bool
+	Dots    []bool
+}
+
+var htmlFuncs = template.FuncMap{
+	"msg":    (*messages.Catalog)(nil).Sprintf,
+	"status": (*messages.Catalog)(nil).Status,
+	"locale": (*messages.Catalog)(nil).Locale,
+	"duration": func(d time.Duration) string {
+		if d == 0 {
+			return "-"
+		}
+		return clock.FormatDuration(d)
+	},
+	"speed": func(speed float64) string {
+		if speed == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.3f", speed)
+	},
+	"badge": func(status string) string {
+		return strings.ToLower(status)
+	},
+	// seq counts from 1 to n to range over columns
+	"seq": func(n int) []int {
+		numbers := make([]int, n)
+		for i := range numbers {
+			numbers[i] = i + 1
+		}
+		return numbers
+	},
+}
+
+// DefaultHTMLTemplate parses the embedded page templates
+func DefaultHTMLTemplate() (*template.Template, error) {
+	return template.New(HTMLPageTemplate).Funcs(htmlFuncs).Parse(defaultHTML)
+}
+
+// ParseHTMLTemplate parses the template files at paths over the default ones, so
+// an override may redefine the whole page or just a part of it like "style"
+func ParseHTMLTemplate(paths ...string) (*template.Template, error) {
+	tmpl, err := DefaultHTMLTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.ParseFiles(paths...)
+}
+
+// RenderHTML writes a self-contained results page with the ranked competitors,
+// their splits, shooting and penalties, and the relay teams if any
+func RenderHTML(w io.Writer, reports []worker.CompetitorReport, teams []worker.TeamReport, opts HTMLOptions) error {
+	tmpl := opts.Template
+	if tmpl == nil {
+		var err error
+		if tmpl, err = DefaultHTMLTemplate(); err != nil {
+			return err
+		}
+	}
+
+	if opts.Messages != nil {
+		var err error
+		if tmpl, err = tmpl.Clone(); err != nil {
+			return err
+		}
+		tmpl.Funcs(template.FuncMap{"msg": opts.Messages.Sprintf, "status": opts.Messages.Status, "locale": opts.Messages.Locale})
+	}
+
+	worker.Rank(reports)
+	worker.RankTeams(teams)
+
+	var layout HTMLSection
+	for _, r := range reports {
+		layout.Laps = max(layout.Laps, len(r.Laps))
+		layout.FiringLines = max(layout.FiringLines, len(r.Shooting))
+	}
+
+	page := HTMLPage{Sections: []HTMLSection{layout.with("", slices.Clone(reports), opts.Competitors)}, Teams: teams}
+	if opts.CategoryBy != "" {
+		tables, err := SplitByCategory(reports, opts.Competitors, opts.CategoryBy)
+		if err != nil {
+			return err
+		}
+		page.Sections[0].Name = opts.Messages.Text("report.overall")
+		for _, table := range tables {
+			page.Sections = append(page.Sections, layout.with(table.Category, table.Reports, opts.Competitors))
+		}
+	}
+
+	return tmpl.ExecuteTemplate(w, HTMLPageTemplate, page)
+}
+
+// with fills a section of the same columns with a row per report
+func (s HTMLSection) with(name string, reports []worker.CompetitorReport, competitors *roster.Roster) HTMLSection {
+	s.Name = name
+	s.Rows = make([]HTMLRow, 0, len(reports))
+	for _, r := range reports {
+		competitor, _ := competitors.Get(r.CompetitorID)
+		row := HTMLRow{CompetitorReport: r, Competitor: competitor, Splits: make([]worker.LapInfo, s.Laps), Ranges: make([]HTMLRange, s.FiringLines)}
+		copy(row.Splits, r.Laps)
+		for i, visit := range r.Shooting {
+			dots := make([]bool, visit.Targets)
+			for target := range dots {
+				dots[target] = visit.Hit(target + 1)
+			}
+			row.Ranges[i] = HTMLRange{FiringVisit: visit, Visited: true, Dots: dots}
+		}
+		s.Rows = append(s.Rows, row)
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:30"}
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Bib: 11, Name: "Anna Berg", Club: "NOR", Gender: "F", Category: "Senior"},
+		{ID: 3, Bib: 13, Name: "Mia <Kossu> Koskinen", Club: "FIN", Gender: "F", Category: "Junior"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+
+	type content struct {
+		name    string
+		reports []worker.CompetitorReport
+		teams   []worker.TeamReport
+		opts    HTMLOptions
+		golden  string
+	}
+
+	tests := []content{
+		{
+			name:    "individual race",
+			reports: raceReports(t, raceConfig),
+			golden:  "report.golden.html",
+		},
+		{
+			name:    "categories",
+			reports: raceReports(t, raceConfig),
+			opts:    HTMLOptions{CategoryBy: roster.ByCategory},
+			golden:  "report_categories.golden.html",
+		},
+		{
+			name: "relay",
+			reports: []worker.CompetitorReport{
+				{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:15:00.000")},
+				{CompetitorID: 2, Status: "Finished", TotalTime: clock.MustParseDuration("00:14:00.000")},
+			},
+			teams: []worker.TeamReport{
+				{
+					Team:      "Blue",
+					Status:    "Finished",
+					TotalTime: clock.MustParseDuration("00:29:10.000"),
+					Legs: []worker.LegReport{
+						{Leg: 1, Time: clock.MustParseDuration("00:15:10.000"), HandedOver: clock.MustParse("10:15:10.000"), Report: worker.CompetitorReport{CompetitorID: 1}},
+						{Leg: 2, Time: clock.MustParseDuration("00:14:00.000"), Report: worker.CompetitorReport{CompetitorID: 2}},
+					},
+				},
+			},
+			golden: "report_relay.golden.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			tt.opts.Competitors = competitors
+			if err := RenderHTML(&got, tt.reports, tt.teams, tt.opts); err != nil {
+				t.Fatalf("RenderHTML() error = %v", err)
+			}
+			golden(t, tt.golden, got.Bytes())
+		})
+	}
+
+	if err := RenderHTML(&bytes.Buffer{}, nil, nil, HTMLOptions{Options: Options{Competitors: competitors}, CategoryBy: "shoe"}); err == nil {
+		t.Error("RenderHTML() with an unknown attribute succeeded")
+	}
+}
+
+func TestParseHTMLTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "venue.html.tmpl")
+	override := `{{define "title"}}Sunny 5 Skiers{{end}}{{define "style"}}body { font-size: 2rem; }{{end}}`
+	if err := os.WriteFile(path, []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := ParseHTMLTemplate(path)
+	if err != nil {
+		t.Fatalf("ParseHTMLTemplate() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{{CompetitorID: 1, Status: "NotStarted"}}
+	var got bytes.Buffer
+	if err := RenderHTML(&got, reports, nil, HTMLOptions{Template: tmpl}); err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+
+	for _, want := range []string{"<title>Sunny 5 Skiers</title>", "body { font-size: 2rem; }", `<span class="badge notstarted">NotStarted</span>`} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("RenderHTML() = %s, want it to contain %q", got.String(), want)
+		}
+	}
+	if strings.Contains(got.String(), ".badge.finished") {
+		t.Error("RenderHTML() kept the default style")
+	}
+
+	if _, err := ParseHTMLTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("ParseHTMLTemplate() of a missing file succeeded")
+	}
+}
+
+func TestRenderHTMLMessages(t *testing.T) {
+	catalog, err := messages.New("de", map[string]string{
+		"html.title":      "Ergebnisse",
+		"html.lap":        "Runde %d",
+		"status.Finished": "Im Ziel",
+		"report.overall":  "Gesamtwertung",
+		"html.competitor": "Teilnehmer %d",
+	})
+	if err != nil {
+		t.Fatalf("messages.New() error = %v", err)
+	}
+	competitors, err := roster.New([]roster.Competitor{{ID: 1, Category: "Senior"}})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:15:00.000"), Laps: []worker.LapInfo{{Time: clock.MustParseDuration("00:15:00.000"), Speed: 3}}},
+	}
+
+	var got bytes.Buffer
+	if err := RenderHTML(&got, reports, nil, HTMLOptions{Options: Options{Competitors: competitors, Messages: catalog}, CategoryBy: roster.ByCategory}); err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	for _, want := range []string{`<html lang="de">`, "<title>Ergebnisse</title>", "<th>Runde 1</th>", `<span class="badge finished">Im Ziel</span>`, "<h2>Gesamtwertung</h2>", "<td>Teilnehmer 1</td>", "<th>Place</th>"} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("RenderHTML() = %s, want it to contain %q", got.String(), want)
+		}
+	}
+}
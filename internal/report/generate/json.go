@@ -0,0 +1,226 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// SchemaVersion of the JSON report, bumped on every incompatible change.
+// The schema is documented in docs/report-json.md.
+const SchemaVersion = 1
+
+type jsonReport struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Competitors   []jsonCompetitor `json:"competitors"`
+	Teams         []jsonTeam       `json:"teams,omitempty"`
+}
+
+type jsonCompetitor struct {
+	ID               int                   `json:"id"`
+	Bib              int                   `json:"bib,omitempty"`
+	Name             string                `json:"name,omitempty"`
+	Club             string                `json:"club,omitempty"`
+	Gender           string                `json:"gender,omitempty"`
+	Category         string                `json:"category,omitempty"`
+	Place            *int                  `json:"place"`
+	Status           string                `json:"status"`
+	TotalTime        *string               `json:"totalTime"`
+	Laps             []jsonLap             `json:"laps"`
+	Penalty          jsonSplit             `json:"penalty"`
+	Penalties        []jsonPenalty         `json:"penalties"`
+	AddedTime        *string               `json:"addedTime,omitempty"`
+	Hits             int                   `json:"hits"`
+	Shots            int                   `json:"shots"`
+	Shooting         []jsonShooting        `json:"shooting"`
+	Disqualification *jsonDisqualification `json:"disqualification,omitempty"`
+	Reason           string                `json:"reason,omitempty"`
+	Violations       []string              `json:"violations,omitempty"`
+}
+
+// jsonSplit is a time with the speed it was skied at, both null when unknown
+type jsonSplit struct {
+	Time  *string  `json:"time"`
+	Speed *float64 `json:"speed"`
+}
+
+type jsonLap struct {
+	Lap int `json:"lap"`
+	jsonSplit
+}
+
+type jsonPenalty struct {
+	FiringRange int `json:"firingRange"`
+	Misses      int `json:"misses"`
+	jsonSplit
+}
+
+type jsonShooting struct {
+	FiringRange int     `json:"firingRange"`
+	Lap         int     `json:"lap"`
+	Position    string  `json:"position,omitempty"`
+	Hits        []int   `json:"hits"`
+	Targets     int     `json:"targets"`
+	Shots       int     `json:"shots"`
+	MaxShots    int     `json:"maxShots"`
+	Misses      int     `json:"misses"`
+	Time        *string `json:"time"`
+}
+
+type jsonDisqualification struct {
+	At     string `json:"at"`
+	Reason string `json:"reason"`
+}
+
+type jsonTeam struct {
+	Team      string    `json:"team"`
+	Place     *int      `json:"place"`
+	Status    string    `json:"status"`
+	TotalTime *string   `json:"totalTime"`
+	Legs      []jsonLeg `json:"legs"`
+}
+
+type jsonLeg struct {
+	Leg          int     `json:"leg"`
+	CompetitorID int     `json:"competitorId"`
+	Time         *string `json:"time"`
+	HandedOver   *string `json:"handedOver"`
+}
+
+// EncodeJSON writes the ranked reports, and the relay teams if any, as an indented
+// JSON document of schema SchemaVersion. Roster details are added when known.
+func EncodeJSON(w io.Writer, reports []worker.CompetitorReport, teams []worker.TeamReport, competitors *roster.Roster) error {
+	worker.Rank(reports)
+	worker.RankTeams(teams)
+
+	document := jsonReport{SchemaVersion: SchemaVersion, Competitors: []jsonCompetitor{}}
+	for _, r := range reports {
+		document.Competitors = append(document.Competitors, newJSONCompetitor(r, competitors))
+	}
+	for _, team := range teams {
+		document.Teams = append(document.Teams, newJSONTeam(team))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func newJSONCompetitor(r worker.CompetitorReport, competitors *roster.Roster) jsonCompetitor {
+	result := jsonCompetitor{
+		ID:        r.CompetitorID,
+		Place:     place(r.Place),
+		Status:    r.Status,
+		TotalTime: duration(r.TotalTime),
+		Laps:      []jsonLap{},
+		Penalty:   split(r.Penalty.Time, r.Penalty.Speed),
+		Penalties: []jsonPenalty{},
+		Hits:      r.Hits(),
+		Shots:     r.Shots(),
+		Shooting:  []jsonShooting{},
+	}
+	if competitor, ok := competitors.Get(r.CompetitorID); ok {
+		result.Bib = competitor.Bib
+		result.Name = competitor.Name
+		result.Club = competitor.Club
+		result.Gender = competitor.Gender
+		result.Category = competitor.Category
+	}
+
+	for i, lap := range r.Laps {
+		result.Laps = append(result.Laps, jsonLap{Lap: i + 1, jsonSplit: split(lap.Time, lap.Speed)})
+	}
+	for _, visit := range r.Penalties {
+		result.Penalties = append(result.Penalties, jsonPenalty{
+			FiringRange: visit.FiringRange,
+			Misses:      visit.Misses,
+			jsonSplit:   split(visit.Time, visit.Speed),
+		})
+	}
+	if r.TimePenalty {
+		addedTime := clock.FormatDuration(r.AddedTime)
+		result.AddedTime = &addedTime
+	}
+	for _, visit := range r.Shooting {
+		hits := slices.Clone(visit.Hits)
+		if hits == nil {
+			hits = []int{}
+		}
+		result.Shooting = append(result.Shooting, jsonShooting{
+			FiringRange: visit.FiringRange,
+			Lap:         visit.Lap,
+			Position:    visit.Position,
+			Hits:        hits,
+			Targets:     visit.Targets,
+			Shots:       visit.Shots,
+			MaxShots:    visit.MaxShots,
+			Misses:      visit.Misses,
+			Time:        duration(visit.Time),
+		})
+	}
+	if !r.DisqualifiedAt.IsZero() {
+		result.Disqualification = &jsonDisqualification{At: r.DisqualifiedAt.String(), Reason: r.Reason}
+	} else if r.Status == "NotFinished" {
+		result.Reason = r.Reason
+	}
+	for _, violation := range r.Violations {
+		result.Violations = append(result.Violations, violation.String())
+	}
+	return result
+}
+
+func newJSONTeam(team worker.TeamReport) jsonTeam {
+	result := jsonTeam{
+		Team:      team.Team,
+		Place:     place(team.Place),
+		Status:    team.Status,
+		TotalTime: duration(team.TotalTime),
+		Legs:      []jsonLeg{},
+	}
+	for _, leg := range team.Legs {
+		var handedOver *string
+		if !leg.HandedOver.IsZero() {
+			at := leg.HandedOver.String()
+			handedOver = &at
+		}
+		result.Legs = append(result.Legs, jsonLeg{
+			Leg:          leg.Leg,
+			CompetitorID: leg.Report.CompetitorID,
+			Time:         duration(leg.Time),
+			HandedOver:   handedOver,
+		})
+	}
+	return result
+}
+
+// place is null for the unplaced
+func place(p int) *int {
+	if p == 0 {
+		return nil
+	}
+	return &p
+}
+
+// duration is null for an unknown zero duration
+func duration(d time.Duration) *string {
+	if d == 0 {
+		return nil
+	}
+	formatted := clock.FormatDuration(d)
+	return &formatted
+}
+
+// split is null in both fields for a split that was not completed, speeds
+// are rounded to the millimetre per second like the text report
+func split(d time.Duration, speed float64) jsonSplit {
+	if d == 0 {
+		return jsonSplit{}
+	}
+	rounded := math.Round(speed*1000) / 1000
+	return jsonSplit{Time: duration(d), Speed: &rounded}
+}
@@ -0,0 +1,131 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with the content of testdata/name, rewriting it with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, rerun with -update if the change is intended\ngot:\n%s", path, got)
+	}
+}
+
+// raceReports processes testdata/race.events like the report command does
+func raceReports(t *testing.T, raceConfig config.Race) []worker.CompetitorReport {
+	t.Helper()
+	ctx := context.Background()
+	eventsFile, err := os.Open(filepath.Join("testdata", "race.events"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventsFile.Close()
+
+	store, parseErrors := events.ParseEvents(ctx, eventsFile, events.Options{FiringLines: raceConfig.FiringLines})
+	if len(parseErrors) > 0 {
+		t.Fatalf("ParseEvents() errors = %v", parseErrors)
+	}
+	worker.GenerateOutgoing(raceConfig, store)
+	reports, err := ReportTable(raceConfig, store.ByCompetitor())
+	if err != nil {
+		t.Fatalf("ReportTable() error = %v", err)
+	}
+	return reports
+}
+
+func TestEncodeJSON(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:30"}
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Bib: 11, Name: "Anna Berg", Club: "NOR", Gender: "F", Category: "Senior"},
+		{ID: 4, Bib: 14, Name: "Jonas Weber", Club: "GER", Gender: "M", Category: "Junior"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+
+	timeConfig := raceConfig
+	timeConfig.PenaltyMode = config.PenaltyTime
+	timeConfig.Stages = []config.Stage{{Position: config.Prone, Lap: 1}, {Position: config.Standing, Lap: 2}}
+
+	type content struct {
+		name        string
+		reports     []worker.CompetitorReport
+		teams       []worker.TeamReport
+		competitors *roster.Roster
+		golden      string
+	}
+
+	tests := []content{
+		{
+			name:        "individual race",
+			reports:     raceReports(t, raceConfig),
+			competitors: competitors,
+			golden:      "report.golden.json",
+		},
+		{
+			name:    "time penalty with stages",
+			reports: raceReports(t, timeConfig),
+			golden:  "report_time_penalty.golden.json",
+		},
+		{
+			name: "relay",
+			reports: []worker.CompetitorReport{
+				{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:15:00.000")},
+				{CompetitorID: 2, Status: "Finished", TotalTime: clock.MustParseDuration("00:14:00.000")},
+			},
+			teams: []worker.TeamReport{
+				{
+					Team:      "Blue",
+					Status:    "Finished",
+					TotalTime: clock.MustParseDuration("00:29:10.000"),
+					Legs: []worker.LegReport{
+						{Leg: 1, Time: clock.MustParseDuration("00:15:10.000"), HandedOver: clock.MustParse("10:15:10.000"), Report: worker.CompetitorReport{CompetitorID: 1}},
+						{Leg: 2, Time: clock.MustParseDuration("00:14:00.000"), Report: worker.CompetitorReport{CompetitorID: 2}},
+					},
+				},
+			},
+			golden: "report_relay.golden.json",
+		},
+		{
+			name:   "empty",
+			golden: "report_empty.golden.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := EncodeJSON(&got, tt.reports, tt.teams, tt.competitors); err != nil {
+				t.Fatalf("EncodeJSON() error = %v", err)
+			}
+			if !json.Valid(got.Bytes()) {
+				t.Fatalf("EncodeJSON() = %s, not valid JSON", got.String())
+			}
+			golden(t, tt.golden, got.Bytes())
+		})
+	}
+}
@@ -1,38 +1,52 @@
 package generate
 
 import (
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/roster"
 	"CompetitionLogger/pkg/events"
 	"fmt"
+	"iter"
 )
 
-var eventComments = map[int]string{
-	1:  "[%s] The competitor(%d) registered",
-	2:  "[%s] The start time for the competitor(%d) was set by a draw to %s",
-	3:  "[%s] The competitor(%d) is on the start line",
-	4:  "[%s] The competitor(%d) has started",
-	5:  "[%s] The competitor(%d) is on the firing range(%s)",
-	6:  "[%s] The target(%s) has been hit by competitor(%d)",
-	7:  "[%s] The competitor(%d) left the firing range",
-	8:  "[%s] The competitor(%d) entered the penalty laps",
-	9:  "[%s] The competitor(%d) left the penalty laps",
-	10: "[%s] The competitor(%d) ended the main lap",
-	11: "[%s] The competitor(%d) can't continue: %s",
-	32: "[%s] The competitor(%d) is disqualified",
-	33: "[%s] The competitor(%d) has finished",
+// Options name and translate the text output, the zero value shows competitor
+// IDs and English
+type Options struct {
+	// Competitors label the competitors by the roster, nil shows their IDs
+	Competitors *roster.Roster
+	// Messages translate the output, nil is English
+	Messages *messages.Catalog
 }
 
-func Log(event events.Event) string {
-	comment, exists := eventComments[event.EventID]
-	if !exists {
-		return fmt.Sprintf("Unknown event %d for competitor %d", event.EventID, event.CompetitorID)
+// Log renders an event as a sentence of the log
+func Log(event events.Event, opts Options) string {
+	catalog := opts.Messages
+	competitor := opts.Competitors.Label(event.CompetitorID)
+	if events.Name(event.EventID) == "Unknown" {
+		return catalog.Sprintf("event.unknown", event.Time, competitor, event.EventID)
 	}
 
+	key := fmt.Sprintf("event.%d", event.EventID)
 	switch event.EventID {
-	case 2, 5, 11:
-		return fmt.Sprintf(comment, event.Time, event.CompetitorID, event.ExtraParams)
-	case 6:
-		return fmt.Sprintf(comment, event.Time, event.ExtraParams, event.CompetitorID)
+	case events.StartTimeDrawn:
+		return catalog.Sprintf(key, event.Time, competitor, event.StartTime)
+	case events.OnFiringRange:
+		return catalog.Sprintf(key, event.Time, competitor, event.FiringRange)
+	case events.TargetHit:
+		return catalog.Sprintf(key, event.Time, competitor, event.Target)
+	case events.CannotContinue:
+		return catalog.Sprintf(key, event.Time, competitor, event.Comment)
 	default:
-		return fmt.Sprintf(comment, event.Time, event.CompetitorID)
+		return catalog.Sprintf(key, event.Time, competitor)
+	}
+}
+
+// Logs renders every event of the chronological stream
+func Logs(all iter.Seq[events.Event], opts Options) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for event := range all {
+			if !yield(Log(event, opts)) {
+				return
+			}
+		}
 	}
 }
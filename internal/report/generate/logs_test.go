@@ -0,0 +1,124 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/messages"
+	"CompetitionLogger/internal/roster"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"slices"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	tests := []struct {
+		name  string
+		event events.Event
+		want  string
+	}{
+		{
+			name:  "registered",
+			event: events.Event{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			want:  "[09:05:59.867] The competitor(1) registered",
+		},
+		{
+			name:  "start time drawn",
+			event: events.Event{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+			want:  "[09:15:00.841] The start time for the competitor(1) was set by a draw to 09:30:00.000",
+		},
+		{
+			name:  "target hit",
+			event: events.Event{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 1},
+			want:  "[09:49:33.123] The target(1) has been hit by competitor(1)",
+		},
+		{
+			name:  "handed over",
+			event: events.Event{Time: clock.MustParse("10:15:00.000"), EventID: 12, CompetitorID: 1},
+			want:  "[10:15:00.000] The competitor(1) handed over to the next leg",
+		},
+		{
+			name:  "finished",
+			event: events.Event{Time: clock.MustParse("10:25:26.047"), EventID: 33, CompetitorID: 1},
+			want:  "[10:25:26.047] The competitor(1) has finished",
+		},
+		{
+			name:  "unknown event",
+			event: events.Event{Time: clock.MustParse("10:25:26.047"), EventID: 99, CompetitorID: 1},
+			want:  "Unknown event 99 for competitor 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Log(tt.event, Options{})
+			if got != tt.want {
+				t.Errorf("Log() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogs(t *testing.T) {
+	stream := slices.Values([]events.Event{
+		{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
+	})
+
+	want := []string{
+		"[09:59:03.872] The competitor(1) ended the main lap",
+		"[09:59:03.872] The competitor(1) can't continue: Lost in the forest",
+	}
+	got := slices.Collect(Logs(stream, Options{}))
+	if !slices.Equal(got, want) {
+		t.Errorf("Logs() = %q, want %q", got, want)
+	}
+}
+
+func TestLogsNamed(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{{ID: 1, Bib: 11, Name: "Anna Berg", Club: "NOR"}})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	stream := slices.Values([]events.Event{
+		{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 2},
+		{Time: clock.MustParse("10:00:00.000"), EventID: 99, CompetitorID: 1},
+	})
+
+	want := []string{
+		"[09:49:33.123] The target(3) has been hit by competitor(Anna Berg)",
+		"[09:59:03.872] The competitor(2) ended the main lap",
+		"Unknown event 99 for competitor Anna Berg",
+	}
+	got := slices.Collect(Logs(stream, Options{Competitors: competitors}))
+	if !slices.Equal(got, want) {
+		t.Errorf("Logs() = %q, want %q", got, want)
+	}
+}
+
+func TestLogsTranslated(t *testing.T) {
+	catalog, err := messages.New("de", map[string]string{
+		"event.2":  "[%[1]s] Die Startzeit von Teilnehmer(%[2]s) wurde ausgelost: %[3]s",
+		"event.6":  "[%[1]s] Scheibe(%[3]d) von Teilnehmer(%[2]s) getroffen",
+		"event.11": "[%[1]s] Teilnehmer(%[2]s) kann nicht weiterlaufen: %[3]s",
+	})
+	if err != nil {
+		t.Fatalf("messages.New() error = %v", err)
+	}
+	stream := slices.Values([]events.Event{
+		{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+		{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 1, Comment: "Skibruch"},
+		{Time: clock.MustParse("10:00:00.000"), EventID: 33, CompetitorID: 1},
+	})
+
+	want := []string{
+		"[09:15:00.841] Die Startzeit von Teilnehmer(1) wurde ausgelost: 09:30:00.000",
+		"[09:49:33.123] Scheibe(3) von Teilnehmer(1) getroffen",
+		"[09:59:03.872] Teilnehmer(1) kann nicht weiterlaufen: Skibruch",
+		"[10:00:00.000] The competitor(1) has finished",
+	}
+	got := slices.Collect(Logs(stream, Options{Messages: catalog}))
+	if !slices.Equal(got, want) {
+		t.Errorf("Logs() = %q, want %q", got, want)
+	}
+}
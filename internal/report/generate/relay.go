@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RelayTable builds the ranked reports of the relay teams in config
+func RelayTable(config config.Race, eventsMap map[int][]events.Event) ([]worker.TeamReport, error) {
+	var teams []worker.TeamReport
+	var errs []error
+
+	for _, team := range config.Teams {
+		report, err := worker.ProcessRelay(config, team, eventsMap)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		teams = append(teams, report)
+	}
+	worker.RankTeams(teams)
+
+	return teams, errors.Join(errs...)
+}
+
+// FormatRelay renders a line per team with the total time and every leg, e.g.
+// "[Finished] Blue 00:30:00.000 [{1: 3 00:15:00.000 5/5}, {2: 4 00:15:00.000 4/5}]"
+func FormatRelay(teams []worker.TeamReport, opts Options) string {
+	var result strings.Builder
+	worker.RankTeams(teams)
+	for _, team := range teams {
+		result.WriteString(fmt.Sprintf("[%s] %s ", opts.Messages.Status(team.Status), team.Team))
+		if team.TotalTime == 0 {
+			result.WriteString("-")
+		} else {
+			result.WriteString(clock.FormatDuration(team.TotalTime))
+		}
+
+		result.WriteString(" [")
+		for i, leg := range team.Legs {
+			result.WriteString(fmt.Sprintf("{%d: %d ", leg.Leg, leg.Report.CompetitorID))
+			if leg.Time == 0 {
+				result.WriteString("-")
+			} else {
+				result.WriteString(clock.FormatDuration(leg.Time))
+			}
+			result.WriteString(fmt.Sprintf(" %d/%d}", leg.Report.Hits(), leg.Report.Shots()))
+			if i < len(team.Legs)-1 {
+				result.WriteString(", ")
+			}
+		}
+		result.WriteString("]\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,45 @@
+package generate
+
+import (
+	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
+	"testing"
+)
+
+func TestFormatRelay(t *testing.T) {
+	teams := []worker.TeamReport{
+		{
+			Team:   "Red",
+			Status: "NotFinished",
+			Legs: []worker.LegReport{
+				{Leg: 1, Time: clock.MustParseDuration("00:16:00.000"), Report: worker.CompetitorReport{CompetitorID: 2}},
+				{Leg: 2, Report: worker.CompetitorReport{CompetitorID: 5}},
+			},
+		},
+		{
+			Team:      "Blue",
+			Status:    "Finished",
+			TotalTime: clock.MustParseDuration("00:30:00.000"),
+			Legs: []worker.LegReport{
+				{Leg: 1, Time: clock.MustParseDuration("00:15:00.000"), Report: worker.CompetitorReport{
+					CompetitorID: 3,
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4, 5}, Targets: 5, Shots: 5}},
+				}},
+				{Leg: 2, Time: clock.MustParseDuration("00:15:00.000"), Report: worker.CompetitorReport{
+					CompetitorID: 4,
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4}, Targets: 5, Shots: 5, Misses: 1}},
+				}},
+			},
+		},
+	}
+
+	want := `[Finished] Blue 00:30:00.000 [{1: 3 00:15:00.000 5/5}, {2: 4 00:15:00.000 4/5}]
+[NotFinished] Red - [{1: 2 00:16:00.000 0/0}, {2: 5 - 0/0}]
+`
+	if got := FormatRelay(teams, Options{}); got != want {
+		t.Errorf("FormatRelay() = %q, want %q", got, want)
+	}
+	if teams[0].Place != 1 || teams[1].Place != 0 {
+		t.Errorf("FormatRelay() places = %d, %d, want 1, 0", teams[0].Place, teams[1].Place)
+	}
+}
@@ -3,37 +3,51 @@ package generate
 import (
 	"CompetitionLogger/internal/config"
 	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
 	"CompetitionLogger/pkg/events"
+	"errors"
 	"fmt"
-	"sort"
 	"strings"
 )
 
-func ReportTable(config config.Race, eventsMap map[int][]events.Event) []worker.CompetitorReport {
+func ReportTable(config config.Race, eventsMap map[int][]events.Event) ([]worker.CompetitorReport, error) {
 	var reports []worker.CompetitorReport
+	var errs []error
 
-	for competitorID, es := range eventsMap {
-		report := worker.ProcessCompetitor(config, competitorID, es)
+	for competitorID, es := range worker.HandOver(config, eventsMap) {
+		report, err := worker.ProcessCompetitor(config, competitorID, es)
+		if err != nil {
+			errs = append(errs, err)
+		}
 		reports = append(reports, report)
 	}
+	worker.Rank(reports)
 
-	return reports
+	return reports, errors.Join(errs...)
 }
 
-func FormatReport(reports []worker.CompetitorReport) string {
+// FormatReport renders a line per competitor in rank order, with the name and
+// club after the ID when the roster has them
+func FormatReport(reports []worker.CompetitorReport, opts Options) string {
+	catalog, competitors := opts.Messages, opts.Competitors
 	var result strings.Builder
-	sort.Slice(reports, func(i, j int) bool {
-		return reports[i].CompetitorID < reports[j].CompetitorID
-	})
+	worker.Rank(reports)
 	for _, r := range reports {
-		result.WriteString(fmt.Sprintf("[%s] %d ", r.Status, r.CompetitorID))
+		result.WriteString(fmt.Sprintf("[%s] %d ", catalog.Status(r.Status), r.CompetitorID))
+		if competitor, ok := competitors.Get(r.CompetitorID); ok && competitor.Name != "" {
+			result.WriteString(competitor.Name)
+			if competitor.Club != "" {
+				result.WriteString(fmt.Sprintf(" (%s)", competitor.Club))
+			}
+			result.WriteString(" ")
+		}
 
 		result.WriteString("[")
 		for i, lap := range r.Laps {
-			if lap.Time == "" {
+			if lap.Time == 0 {
 				result.WriteString("{,}")
 			} else {
-				result.WriteString(fmt.Sprintf("{%.12s, %.3f}", lap.Time, lap.Speed))
+				result.WriteString(fmt.Sprintf("{%s, %.3f}", clock.FormatDuration(lap.Time), lap.Speed))
 			}
 			if i < len(r.Laps)-1 {
 				result.WriteString(", ")
@@ -41,16 +55,41 @@ func FormatReport(reports []worker.CompetitorReport) string {
 		}
 		result.WriteString("] ")
 
-		if r.Penalty.Time == "" {
+		if r.Penalty.Time == 0 {
 			result.WriteString("{,}")
 		} else {
-			result.WriteString(fmt.Sprintf("{%.12s, %.3f}", r.Penalty.Time, r.Penalty.Speed))
+			result.WriteString(fmt.Sprintf("{%s, %.3f}", clock.FormatDuration(r.Penalty.Time), r.Penalty.Speed))
 		}
 		result.WriteString(" ")
 
-		result.WriteString(r.HitsShots)
+		if r.TimePenalty {
+			result.WriteString(fmt.Sprintf("+%s ", clock.FormatDuration(r.AddedTime)))
+		}
+
+		result.WriteString(fmt.Sprintf("%d/%d", r.Hits(), r.Shots()))
+		for _, position := range r.ByPosition() {
+			result.WriteString(fmt.Sprintf(" %s %d/%d", position.Position, position.Hits, position.Shots))
+		}
+
+		if !r.DisqualifiedAt.IsZero() {
+			result.WriteString(fmt.Sprintf(" (%s)", catalog.Sprintf("report.disqualified", r.DisqualifiedAt, r.Reason)))
+		}
+		if !r.Consistent() {
+			result.WriteString(fmt.Sprintf(" (%s)", catalog.Sprintf("report.inconsistent", len(r.Violations))))
+		}
 
 		result.WriteString("\n")
 	}
 	return result.String()
 }
+
+// FormatViolations lists the impossible events of every inconsistent competitor
+func FormatViolations(reports []worker.CompetitorReport) string {
+	var result strings.Builder
+	for _, r := range reports {
+		for _, violation := range r.Violations {
+			result.WriteString(fmt.Sprintf("competitor(%d): %s\n", r.CompetitorID, violation))
+		}
+	}
+	return result.String()
+}
@@ -2,11 +2,15 @@ package generate
 
 import (
 	"CompetitionLogger/internal/config"
+	"CompetitionLogger/internal/roster"
 	"CompetitionLogger/internal/worker"
+	"CompetitionLogger/pkg/clock"
 	"CompetitionLogger/pkg/events"
 	"reflect"
+	"slices"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestReportTable(t *testing.T) {
@@ -21,57 +25,85 @@ func TestReportTable(t *testing.T) {
 		name      string
 		eventsMap map[int][]events.Event
 		want      []worker.CompetitorReport
+		wantErr   bool
 	}{
 		{
 			name: "multiple competitors",
 			eventsMap: map[int][]events.Event{
 				1: {
-					{Time: "09:00:00.000", EventID: 2, CompetitorID: 1, ExtraParams: "09:00:00.000"},
-					{Time: "09:00:01.000", EventID: 4, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:05:00.000", EventID: 10, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:10:00.000", EventID: 10, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:15:00.000", EventID: 33, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:02:00.000", EventID: 5, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:02:01.000", EventID: 6, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:02:02.000", EventID: 6, CompetitorID: 1, ExtraParams: ""},
+					{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")},
+					{Time: clock.MustParse("09:00:01.000"), EventID: 4, CompetitorID: 1},
+					{Time: clock.MustParse("09:05:00.000"), EventID: 10, CompetitorID: 1},
+					{Time: clock.MustParse("09:10:00.000"), EventID: 10, CompetitorID: 1},
+					{Time: clock.MustParse("09:15:00.000"), EventID: 33, CompetitorID: 1},
+					{Time: clock.MustParse("09:02:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+					{Time: clock.MustParse("09:02:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+					{Time: clock.MustParse("09:02:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
 				},
 				2: {
-					{Time: "09:00:00.000", EventID: 2, CompetitorID: 2, ExtraParams: "09:00:00.000"},
-					{Time: "09:00:01.000", EventID: 4, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:06:00.000", EventID: 10, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:12:00.000", EventID: 10, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:18:00.000", EventID: 33, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:03:00.000", EventID: 5, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:03:01.000", EventID: 6, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:08:00.000", EventID: 8, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:08:30.000", EventID: 9, CompetitorID: 2, ExtraParams: ""},
+					{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 2, StartTime: clock.MustParse("09:00:00.000")},
+					{Time: clock.MustParse("09:00:01.000"), EventID: 4, CompetitorID: 2},
+					{Time: clock.MustParse("09:06:00.000"), EventID: 10, CompetitorID: 2},
+					{Time: clock.MustParse("09:12:00.000"), EventID: 10, CompetitorID: 2},
+					{Time: clock.MustParse("09:18:00.000"), EventID: 33, CompetitorID: 2},
+					{Time: clock.MustParse("09:03:00.000"), EventID: 5, CompetitorID: 2, FiringRange: 1},
+					{Time: clock.MustParse("09:03:01.000"), EventID: 6, CompetitorID: 2, Target: 1},
+					{Time: clock.MustParse("09:08:00.000"), EventID: 8, CompetitorID: 2},
+					{Time: clock.MustParse("09:08:30.000"), EventID: 9, CompetitorID: 2},
 				},
 			},
 			want: []worker.CompetitorReport{
 				{
 					CompetitorID: 1,
+					Place:        1,
 					Status:       "Finished",
-					TotalTime:    "00:15:00.000",
+					TotalTime:    clock.MustParseDuration("00:15:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "00:04:59.000", Speed: 3651.0 / worker.TimeToSeconds("00:04:59.000")},
-						{Time: "00:05:00.000", Speed: 3651.0 / worker.TimeToSeconds("00:05:00.000")},
+						{Time: clock.MustParseDuration("00:04:59.000"), Speed: 3651.0 / clock.MustParseDuration("00:04:59.000").Seconds()},
+						{Time: clock.MustParseDuration("00:05:00.000"), Speed: 3651.0 / clock.MustParseDuration("00:05:00.000").Seconds()},
+					},
+					Penalty: worker.PenaltyInfo{Time: 0, Speed: 0.0},
+					Shooting: []worker.FiringVisit{
+						{FiringRange: 1, Lap: 3, Hits: []int{1, 2}, Targets: 5, Shots: 5, MaxShots: 5, Misses: 3},
+					},
+					Violations: []worker.Violation{
+						{Event: events.Event{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")}, State: worker.Unregistered},
+						{Event: events.Event{Time: clock.MustParse("09:02:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1}, State: worker.Finished},
+						{Event: events.Event{Time: clock.MustParse("09:02:01.000"), EventID: 6, CompetitorID: 1, Target: 1}, State: worker.Finished},
+						{Event: events.Event{Time: clock.MustParse("09:02:02.000"), EventID: 6, CompetitorID: 1, Target: 2}, State: worker.Finished},
 					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "2/5",
 				},
 				{
 					CompetitorID: 2,
+					Place:        2,
 					Status:       "Finished",
-					TotalTime:    "00:18:00.000",
+					TotalTime:    clock.MustParseDuration("00:18:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "00:05:59.000", Speed: 3651.0 / worker.TimeToSeconds("00:05:59.000")},
-						{Time: "00:06:00.000", Speed: 3651.0 / worker.TimeToSeconds("00:06:00.000")},
+						{Time: clock.MustParseDuration("00:05:59.000"), Speed: 3651.0 / clock.MustParseDuration("00:05:59.000").Seconds()},
+						{Time: clock.MustParseDuration("00:06:00.000"), Speed: 3651.0 / clock.MustParseDuration("00:06:00.000").Seconds()},
 					},
 					Penalty: worker.PenaltyInfo{
-						Time:  "00:00:30.000",
-						Speed: float64(50*4) / worker.TimeToSeconds("00:00:30.000"),
+						Time:  clock.MustParseDuration("00:00:30.000"),
+						Speed: float64(50*4) / clock.MustParseDuration("00:00:30.000").Seconds(),
+					},
+					Penalties: []worker.PenaltyVisit{
+						{
+							FiringRange: 1,
+							Misses:      4,
+							Time:        clock.MustParseDuration("00:00:30.000"),
+							Speed:       float64(50*4) / clock.MustParseDuration("00:00:30.000").Seconds(),
+						},
+					},
+					Shooting: []worker.FiringVisit{
+						{FiringRange: 1, Lap: 3, Hits: []int{1}, Targets: 5, Shots: 5, MaxShots: 5, Misses: 4},
+					},
+					Violations: []worker.Violation{
+						{Event: events.Event{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 2, StartTime: clock.MustParse("09:00:00.000")}, State: worker.Unregistered},
+						{Event: events.Event{Time: clock.MustParse("09:03:00.000"), EventID: 5, CompetitorID: 2, FiringRange: 1}, State: worker.Finished},
+						{Event: events.Event{Time: clock.MustParse("09:03:01.000"), EventID: 6, CompetitorID: 2, Target: 1}, State: worker.Finished},
+						{Event: events.Event{Time: clock.MustParse("09:08:00.000"), EventID: 8, CompetitorID: 2}, State: worker.Finished},
+						{Event: events.Event{Time: clock.MustParse("09:08:30.000"), EventID: 9, CompetitorID: 2}, State: worker.Finished},
 					},
-					HitsShots: "1/5",
 				},
 			},
 		},
@@ -84,20 +116,22 @@ func TestReportTable(t *testing.T) {
 			name: "single competitor not started",
 			eventsMap: map[int][]events.Event{
 				1: {
-					{Time: "09:00:00.000", EventID: 2, CompetitorID: 1, ExtraParams: "09:00:00.000"},
+					{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")},
 				},
 			},
 			want: []worker.CompetitorReport{
 				{
 					CompetitorID: 1,
 					Status:       "NotStarted",
-					TotalTime:    "00:00:00.000",
+					TotalTime:    clock.MustParseDuration("00:00:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "", Speed: 0.0},
-						{Time: "", Speed: 0.0},
+						{Time: 0, Speed: 0.0},
+						{Time: 0, Speed: 0.0},
+					},
+					Penalty: worker.PenaltyInfo{Time: 0, Speed: 0.0},
+					Violations: []worker.Violation{
+						{Event: events.Event{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")}, State: worker.Unregistered},
 					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "0/0",
 				},
 			},
 		},
@@ -105,55 +139,50 @@ func TestReportTable(t *testing.T) {
 			name: "competitor not finished",
 			eventsMap: map[int][]events.Event{
 				1: {
-					{Time: "09:00:00.000", EventID: 2, CompetitorID: 1, ExtraParams: "09:00:00.000"},
-					{Time: "09:00:01.000", EventID: 4, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:05:00.000", EventID: 10, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:10:00.000", EventID: 11, CompetitorID: 1, ExtraParams: ""},
+					{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")},
+					{Time: clock.MustParse("09:00:01.000"), EventID: 4, CompetitorID: 1},
+					{Time: clock.MustParse("09:05:00.000"), EventID: 10, CompetitorID: 1},
+					{Time: clock.MustParse("09:10:00.000"), EventID: 11, CompetitorID: 1},
 				},
 			},
 			want: []worker.CompetitorReport{
 				{
 					CompetitorID: 1,
 					Status:       "NotFinished",
-					TotalTime:    "00:10:00.000",
+					TotalTime:    clock.MustParseDuration("00:10:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "00:04:59.000", Speed: 3651.0 / worker.TimeToSeconds("00:04:59.000")},
-						{Time: "", Speed: 0.0},
+						{Time: clock.MustParseDuration("00:04:59.000"), Speed: 3651.0 / clock.MustParseDuration("00:04:59.000").Seconds()},
+						{Time: 0, Speed: 0.0},
+					},
+					Penalty: worker.PenaltyInfo{Time: 0, Speed: 0.0},
+					Violations: []worker.Violation{
+						{Event: events.Event{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:00:00.000")}, State: worker.Unregistered},
 					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "0/0",
 				},
 			},
 		},
 		{
-			name: "invalid time format",
+			name: "missing start time",
 			eventsMap: map[int][]events.Event{
 				1: {
-					{Time: "09:00:00.000", EventID: 2, CompetitorID: 1, ExtraParams: "09:00:00.000"},
-					{Time: "09:00:01.000", EventID: 4, CompetitorID: 1, ExtraParams: ""},
-					{Time: "invalid", EventID: 10, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:15:00.000", EventID: 33, CompetitorID: 1, ExtraParams: ""},
-				},
-			},
-			want: []worker.CompetitorReport{
-				{
-					CompetitorID: 1,
-					Status:       "Finished",
-					TotalTime:    "00:15:00.000",
-					Laps: []worker.LapInfo{
-						{Time: "00:00:00.000", Speed: 0.0},
-						{Time: "", Speed: 0.0},
-					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "0/0",
+					{Time: clock.MustParse("09:00:00.000"), EventID: 2, CompetitorID: 1},
+					{Time: clock.MustParse("09:00:01.000"), EventID: 4, CompetitorID: 1},
+					{Time: clock.MustParse("09:15:00.000"), EventID: 33, CompetitorID: 1},
 				},
 			},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ReportTable(raceConfig, tt.eventsMap)
+			got, err := ReportTable(raceConfig, tt.eventsMap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReportTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
 			if len(got) != len(tt.want) {
 				t.Errorf("ReportTable() len = %d, want %d", len(got), len(tt.want))
 			}
@@ -174,6 +203,64 @@ func TestReportTable(t *testing.T) {
 	}
 }
 
+func TestReportTableRelay(t *testing.T) {
+	raceConfig := config.Race{
+		Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartMass,
+		Teams: []config.Team{{Name: "Blue", Members: []int{1, 2}}},
+	}
+	leg := func(competitorID int, start, finish string) []events.Event {
+		return []events.Event{
+			{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: competitorID},
+			{Time: clock.MustParse(start), EventID: 4, CompetitorID: competitorID},
+			{Time: clock.MustParse(finish), EventID: 10, CompetitorID: competitorID},
+			{Time: clock.MustParse(finish), EventID: 33, CompetitorID: competitorID},
+		}
+	}
+	handedOver := append(leg(1, "10:00:02.000", "10:10:00.000"),
+		events.Event{Time: clock.MustParse("10:10:03.000"), EventID: 12, CompetitorID: 1})
+
+	type content struct {
+		name      string
+		second    []events.Event
+		status    string
+		totalTime time.Duration
+	}
+
+	tests := []content{
+		{
+			name:      "leg starts at the handover",
+			second:    leg(2, "10:10:05.000", "10:21:00.000"),
+			status:    "Finished",
+			totalTime: clock.MustParseDuration("00:10:57.000"),
+		},
+		{
+			name:   "leg starts after the window",
+			second: leg(2, "10:11:00.000", "10:21:00.000"),
+			status: "NotStarted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReportTable(raceConfig, map[int][]events.Event{1: handedOver, 2: tt.second})
+			if err != nil {
+				t.Fatalf("ReportTable() error = %v", err)
+			}
+			idx := slices.IndexFunc(got, func(r worker.CompetitorReport) bool { return r.CompetitorID == 2 })
+			if idx < 0 {
+				t.Fatalf("ReportTable() = %v, want the second leg", got)
+			}
+			second := got[idx]
+			if second.Status != tt.status || second.TotalTime != tt.totalTime {
+				t.Errorf("second leg = %s in %v, want %s in %v", second.Status, second.TotalTime, tt.status, tt.totalTime)
+			}
+			if tt.status == "Finished" && !second.Consistent() {
+				t.Errorf("second leg violations = %v, want none", second.Violations)
+			}
+		})
+	}
+}
+
 func TestFormatReport(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -186,27 +273,27 @@ func TestFormatReport(t *testing.T) {
 				{
 					CompetitorID: 2,
 					Status:       "Finished",
-					TotalTime:    "00:18:00.000",
+					TotalTime:    clock.MustParseDuration("00:18:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "00:05:59.000", Speed: 3651.0 / worker.TimeToSeconds("00:05:59.000")},
-						{Time: "00:06:00.000", Speed: 3651.0 / worker.TimeToSeconds("00:06:00.000")},
+						{Time: clock.MustParseDuration("00:05:59.000"), Speed: 3651.0 / clock.MustParseDuration("00:05:59.000").Seconds()},
+						{Time: clock.MustParseDuration("00:06:00.000"), Speed: 3651.0 / clock.MustParseDuration("00:06:00.000").Seconds()},
 					},
 					Penalty: worker.PenaltyInfo{
-						Time:  "00:00:30.000",
-						Speed: float64(50*4) / worker.TimeToSeconds("00:00:30.000"),
+						Time:  clock.MustParseDuration("00:00:30.000"),
+						Speed: float64(50*4) / clock.MustParseDuration("00:00:30.000").Seconds(),
 					},
-					HitsShots: "1/5",
+					Shooting: []worker.FiringVisit{{FiringRange: 1, Hits: []int{1}, Targets: 5, Shots: 5, Misses: 4}},
 				},
 				{
 					CompetitorID: 1,
 					Status:       "Finished",
-					TotalTime:    "00:15:00.000",
+					TotalTime:    clock.MustParseDuration("00:15:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "00:04:59.000", Speed: 3651.0 / worker.TimeToSeconds("00:04:59.000")},
-						{Time: "00:05:00.000", Speed: 3651.0 / worker.TimeToSeconds("00:05:00.000")},
+						{Time: clock.MustParseDuration("00:04:59.000"), Speed: 3651.0 / clock.MustParseDuration("00:04:59.000").Seconds()},
+						{Time: clock.MustParseDuration("00:05:00.000"), Speed: 3651.0 / clock.MustParseDuration("00:05:00.000").Seconds()},
 					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "2/5",
+					Penalty:  worker.PenaltyInfo{Time: 0, Speed: 0.0},
+					Shooting: []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2}, Targets: 5, Shots: 5, Misses: 3}},
 				},
 			},
 			want: `[Finished] 1 [{00:04:59.000, 12.211}, {00:05:00.000, 12.170}] {,} 2/5
@@ -224,13 +311,12 @@ func TestFormatReport(t *testing.T) {
 				{
 					CompetitorID: 1,
 					Status:       "NotStarted",
-					TotalTime:    "00:00:00.000",
+					TotalTime:    clock.MustParseDuration("00:00:00.000"),
 					Laps: []worker.LapInfo{
-						{Time: "", Speed: 0.0},
-						{Time: "", Speed: 0.0},
+						{Time: 0, Speed: 0.0},
+						{Time: 0, Speed: 0.0},
 					},
-					Penalty:   worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots: "0/0",
+					Penalty: worker.PenaltyInfo{Time: 0, Speed: 0.0},
 				},
 			},
 			want: `[NotStarted] 1 [{,}, {,}] {,} 0/0
@@ -242,19 +328,130 @@ func TestFormatReport(t *testing.T) {
 				{
 					CompetitorID: 1,
 					Status:       "Started",
-					TotalTime:    "00:00:30.000",
+					TotalTime:    clock.MustParseDuration("00:00:30.000"),
 					Laps: []worker.LapInfo{
-						{Time: "", Speed: 0.0},
-						{Time: "", Speed: 0.0},
+						{Time: 0, Speed: 0.0},
+						{Time: 0, Speed: 0.0},
 					},
 					Penalty: worker.PenaltyInfo{
-						Time:  "00:00:30.000",
-						Speed: float64(50*4) / worker.TimeToSeconds("00:00:30.000"),
+						Time:  clock.MustParseDuration("00:00:30.000"),
+						Speed: float64(50*4) / clock.MustParseDuration("00:00:30.000").Seconds(),
 					},
-					HitsShots: "0/5",
+					Shooting: []worker.FiringVisit{{FiringRange: 1, Hits: []int{}, Targets: 5, Shots: 5, Misses: 5}},
 				},
 			},
 			want: `[Started] 1 [{,}, {,}] {00:00:30.000, 6.667} 0/5
+`,
+		},
+		{
+			name: "sorted by total time",
+			reports: []worker.CompetitorReport{
+				{
+					CompetitorID: 1,
+					Status:       "NotStarted",
+					Laps:         []worker.LapInfo{{}},
+				},
+				{
+					CompetitorID: 2,
+					Status:       "Finished",
+					TotalTime:    clock.MustParseDuration("00:20:00.000"),
+					Laps:         []worker.LapInfo{{}},
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4, 5}, Targets: 5, Shots: 5, Misses: 0}},
+				},
+				{
+					CompetitorID: 3,
+					Status:       "NotFinished",
+					TotalTime:    clock.MustParseDuration("00:10:00.000"),
+					Laps:         []worker.LapInfo{{}},
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4, 5}, Targets: 5, Shots: 5, Misses: 0}},
+				},
+				{
+					CompetitorID: 4,
+					Status:       "Finished",
+					TotalTime:    clock.MustParseDuration("00:19:00.000"),
+					Laps:         []worker.LapInfo{{}},
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4}, Targets: 5, Shots: 5, Misses: 1}},
+				},
+			},
+			want: `[Finished] 4 [{,}] {,} 4/5
+[Finished] 2 [{,}] {,} 5/5
+[NotFinished] 3 [{,}] {,} 5/5
+[NotStarted] 1 [{,}] {,} 0/0
+`,
+		},
+		{
+			name: "disqualified competitor",
+			reports: []worker.CompetitorReport{
+				{
+					CompetitorID: 1,
+					Status:       "NotStarted",
+					TotalTime:    clock.MustParseDuration("00:00:00.000"),
+					Laps: []worker.LapInfo{
+						{Time: 0, Speed: 0.0},
+						{Time: 0, Speed: 0.0},
+					},
+					Penalty:        worker.PenaltyInfo{Time: 0, Speed: 0.0},
+					DisqualifiedAt: clock.MustParse("09:30:30.000"),
+					Reason:         "did not start",
+				},
+			},
+			want: `[NotStarted] 1 [{,}, {,}] {,} 0/0 (disqualified at 09:30:30.000: did not start)
+`,
+		},
+		{
+			name: "inconsistent competitor",
+			reports: []worker.CompetitorReport{
+				{
+					CompetitorID: 1,
+					Status:       "Started",
+					Laps:         []worker.LapInfo{{}},
+					Violations: []worker.Violation{
+						{Event: events.Event{Time: clock.MustParse("09:30:00.000"), EventID: 6, CompetitorID: 1, Target: 1}, State: worker.Racing},
+					},
+				},
+			},
+			want: `[Started] 1 [{,}] {,} 0/0 (inconsistent: 1 violations)
+`,
+		},
+		{
+			name: "shooting positions",
+			reports: []worker.CompetitorReport{
+				{
+					CompetitorID: 1,
+					Status:       "Started",
+					Laps:         []worker.LapInfo{{}},
+					Shooting: []worker.FiringVisit{
+						{FiringRange: 1, Position: "prone", Hits: []int{1, 2, 3}, Targets: 5, Shots: 5},
+						{FiringRange: 2, Position: "standing", Hits: []int{1}, Targets: 5, Shots: 5},
+					},
+				},
+			},
+			want: `[Started] 1 [{,}] {,} 4/10 prone 3/5 standing 1/5
+`,
+		},
+		{
+			name: "time penalty",
+			reports: []worker.CompetitorReport{
+				{
+					CompetitorID: 1,
+					Status:       "Finished",
+					TotalTime:    clock.MustParseDuration("00:22:00.000"),
+					Laps:         []worker.LapInfo{{Time: clock.MustParseDuration("00:20:00.000"), Speed: 2.917}},
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3}, Targets: 5, Shots: 5, Misses: 2}},
+					TimePenalty:  true,
+					AddedTime:    clock.MustParseDuration("00:02:00.000"),
+				},
+				{
+					CompetitorID: 2,
+					Status:       "Finished",
+					TotalTime:    clock.MustParseDuration("00:21:00.000"),
+					Laps:         []worker.LapInfo{{Time: clock.MustParseDuration("00:21:00.000"), Speed: 2.778}},
+					Shooting:     []worker.FiringVisit{{FiringRange: 1, Hits: []int{1, 2, 3, 4, 5}, Targets: 5, Shots: 5}},
+					TimePenalty:  true,
+				},
+			},
+			want: `[Finished] 2 [{00:21:00.000, 2.778}] {,} +00:00:00.000 5/5
+[Finished] 1 [{00:20:00.000, 2.917}] {,} +00:02:00.000 3/5
 `,
 		},
 		{
@@ -263,10 +460,9 @@ func TestFormatReport(t *testing.T) {
 				{
 					CompetitorID: 1,
 					Status:       "NotStarted",
-					TotalTime:    "00:00:00.000",
+					TotalTime:    clock.MustParseDuration("00:00:00.000"),
 					Laps:         nil,
-					Penalty:      worker.PenaltyInfo{Time: "", Speed: 0.0},
-					HitsShots:    "0/0",
+					Penalty:      worker.PenaltyInfo{Time: 0, Speed: 0.0},
 				},
 			},
 			want: `[NotStarted] 1 [] {,} 0/0
@@ -276,10 +472,53 @@ func TestFormatReport(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := FormatReport(tt.reports)
+			got := FormatReport(tt.reports, Options{})
 			if got != tt.want {
 				t.Errorf("FormatReport() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestFormatReportNamed(t *testing.T) {
+	competitors, err := roster.New([]roster.Competitor{
+		{ID: 1, Name: "Anna Berg", Club: "NOR"},
+		{ID: 2, Name: "Ole Dahl"},
+	})
+	if err != nil {
+		t.Fatalf("roster.New() error = %v", err)
+	}
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 3, Status: "NotStarted"},
+		{CompetitorID: 2, Status: "Started", Laps: []worker.LapInfo{{}}},
+		{CompetitorID: 1, Status: "Finished", TotalTime: clock.MustParseDuration("00:20:00.000"), Laps: []worker.LapInfo{{Time: clock.MustParseDuration("00:20:00.000"), Speed: 2.5}}},
+	}
+
+	want := `[Finished] 1 Anna Berg (NOR) [{00:20:00.000, 2.500}] {,} 0/0
+[Started] 2 Ole Dahl [{,}] {,} 0/0
+[NotStarted] 3 [] {,} 0/0
+`
+	if got := FormatReport(reports, Options{Competitors: competitors}); got != want {
+		t.Errorf("FormatReport() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatViolations(t *testing.T) {
+	reports := []worker.CompetitorReport{
+		{CompetitorID: 1},
+		{
+			CompetitorID: 2,
+			Violations: []worker.Violation{
+				{Event: events.Event{Time: clock.MustParse("09:30:00.000"), EventID: 6, CompetitorID: 2, Target: 1}, State: worker.Racing},
+				{Event: events.Event{Time: clock.MustParse("09:31:00.000"), EventID: 9, CompetitorID: 2}, State: worker.Racing},
+			},
+		},
+	}
+
+	want := `competitor(2): [09:30:00.000] event 6 is not allowed in state Racing
+competitor(2): [09:31:00.000] event 9 is not allowed in state Racing
+`
+	if got := FormatViolations(reports); got != want {
+		t.Errorf("FormatViolations() = %q, want %q", got, want)
+	}
+}
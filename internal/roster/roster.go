@@ -0,0 +1,196 @@
+package roster
+
+import (
+	"CompetitionLogger/pkg/events"
+	"CompetitionLogger/pkg/logger"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrDuplicate = errors.New("duplicate competitor")
+
+// Competitor is a registered athlete, Club holds the club or the nation
+type Competitor struct {
+	ID       int    `json:"id"`
+	Bib      int    `json:"bib"`
+	Name     string `json:"name"`
+	Club     string `json:"club"`
+	Gender   string `json:"gender"`
+	Category string `json:"category"`
+}
+
+// Attributes a roster can split results by
+const (
+	ByCategory = "category"
+	ByGender   = "gender"
+	ByClub     = "club"
+)
+
+// Attribute returns the value of a competitor attribute by its name, false for
+// an unknown attribute
+func (c Competitor) Attribute(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case ByCategory:
+		return c.Category, true
+	case ByGender:
+		return c.Gender, true
+	case ByClub:
+		return c.Club, true
+	}
+	return "", false
+}
+
+// Roster maps competitor IDs to the registered athletes. A nil Roster is an
+// empty one, so outputs fall back to bare IDs.
+type Roster struct {
+	competitors map[int]Competitor
+}
+
+func New(competitors []Competitor) (*Roster, error) {
+	r := &Roster{competitors: make(map[int]Competitor, len(competitors))}
+	for _, competitor := range competitors {
+		if _, ok := r.competitors[competitor.ID]; ok {
+			return nil, fmt.Errorf("%w %d", ErrDuplicate, competitor.ID)
+		}
+		r.competitors[competitor.ID] = competitor
+	}
+	return r, nil
+}
+
+// Load reads a roster file, .csv files as CSV and everything else as JSON
+func Load(ctx context.Context, pathToRoster string) (*Roster, error) {
+	rosterFile, err := os.Open(pathToRoster)
+	if err != nil {
+		return nil, err
+	}
+	defer rosterFile.Close()
+
+	var competitors []Competitor
+	if strings.EqualFold(filepath.Ext(pathToRoster), ".csv") {
+		competitors, err = ParseCSV(rosterFile)
+	} else {
+		competitors, err = ParseJSON(rosterFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.GetFromContext(ctx).Info("success loading roster", zap.Int("competitors", len(competitors)))
+	return New(competitors)
+}
+
+// ParseJSON reads an array of competitors
+func ParseJSON(r io.Reader) ([]Competitor, error) {
+	var competitors []Competitor
+	if err := json.NewDecoder(r).Decode(&competitors); err != nil {
+		return nil, fmt.Errorf("roster: %w", err)
+	}
+	return competitors, nil
+}
+
+// ParseCSV reads competitors from CSV with a header row. The columns are found
+// by name: id is required, bib, name, club, gender and category are optional.
+func ParseCSV(r io.Reader) ([]Competitor, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("roster header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, errors.New("roster header: missing id column")
+	}
+
+	var competitors []Competitor
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("roster: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		competitor := Competitor{
+			Name:     get("name"),
+			Club:     get("club"),
+			Gender:   get("gender"),
+			Category: get("category"),
+		}
+		if competitor.ID, err = strconv.Atoi(get("id")); err != nil {
+			return nil, fmt.Errorf("roster line %d: bad id %q", line, get("id"))
+		}
+		if bib := get("bib"); bib != "" {
+			if competitor.Bib, err = strconv.Atoi(bib); err != nil {
+				return nil, fmt.Errorf("roster line %d: bad bib %q", line, bib)
+			}
+		}
+		competitors = append(competitors, competitor)
+	}
+	return competitors, nil
+}
+
+// Get finds a competitor by ID
+func (r *Roster) Get(competitorID int) (Competitor, bool) {
+	if r == nil {
+		return Competitor{}, false
+	}
+	competitor, ok := r.competitors[competitorID]
+	return competitor, ok
+}
+
+// Len returns the number of registered competitors
+func (r *Roster) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.competitors)
+}
+
+// Label is how logs refer to a competitor: the name if registered with one,
+// the ID otherwise
+func (r *Roster) Label(competitorID int) string {
+	if competitor, ok := r.Get(competitorID); ok && competitor.Name != "" {
+		return competitor.Name
+	}
+	return strconv.Itoa(competitorID)
+}
+
+// Unknown lists in ascending order the competitor IDs of events missing from the roster
+func (r *Roster) Unknown(all iter.Seq[events.Event]) []int {
+	var unknown []int
+	for event := range all {
+		if _, ok := r.Get(event.CompetitorID); ok {
+			continue
+		}
+		if !slices.Contains(unknown, event.CompetitorID) {
+			unknown = append(unknown, event.CompetitorID)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
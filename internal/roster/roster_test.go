@@ -0,0 +1,145 @@
+package roster
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	type content struct {
+		name     string
+		input    string
+		expected []Competitor
+		wantErr  bool
+	}
+
+	tests := []content{
+		{
+			name:  "all columns",
+			input: "id,bib,name,club,gender,category\n1,11,Anna Berg,NOR,F,Senior\n2,12,\"Ole, Jr.\",SWE,M,Junior\n",
+			expected: []Competitor{
+				{ID: 1, Bib: 11, Name: "Anna Berg", Club: "NOR", Gender: "F", Category: "Senior"},
+				{ID: 2, Bib: 12, Name: "Ole, Jr.", Club: "SWE", Gender: "M", Category: "Junior"},
+			},
+		},
+		{
+			name:     "columns in any order",
+			input:    "Name, ID\nAnna Berg, 1\n",
+			expected: []Competitor{{ID: 1, Name: "Anna Berg"}},
+		},
+		{
+			name:    "missing id column",
+			input:   "name,bib\nAnna Berg,11\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad id",
+			input:   "id,name\none,Anna Berg\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad bib",
+			input:   "id,bib\n1,eleven\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseCSV() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	input := `[{"id": 1, "bib": 11, "name": "Anna Berg", "club": "NOR", "gender": "F", "category": "Senior"}]`
+	got, err := ParseJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	expected := []Competitor{{ID: 1, Bib: 11, Name: "Anna Berg", Club: "NOR", Gender: "F", Category: "Senior"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseJSON() = %v, want %v", got, expected)
+	}
+
+	if _, err := ParseJSON(strings.NewReader(`{"id": 1}`)); err == nil {
+		t.Errorf("ParseJSON() error = nil, want an error for an object")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "roster.csv")
+	jsonPath := filepath.Join(dir, "roster.json")
+	if err := os.WriteFile(csvPath, []byte("id,name\n1,Anna Berg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(`[{"id": 1, "name": "Anna Berg"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{csvPath, jsonPath} {
+		r, err := Load(ctx, path)
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", path, err)
+		}
+		if competitor, ok := r.Get(1); !ok || competitor.Name != "Anna Berg" {
+			t.Errorf("Load(%s).Get(1) = %v, %v, want Anna Berg", path, competitor, ok)
+		}
+	}
+
+	if _, err := Load(ctx, filepath.Join(dir, "missing.csv")); err == nil {
+		t.Errorf("Load() error = nil, want an error for a missing file")
+	}
+}
+
+func TestRoster(t *testing.T) {
+	r, err := New([]Competitor{{ID: 1, Name: "Anna Berg"}, {ID: 3}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got := r.Label(1); got != "Anna Berg" {
+		t.Errorf("Label(1) = %q, want %q", got, "Anna Berg")
+	}
+	if got := r.Label(3); got != "3" {
+		t.Errorf("Label(3) = %q, want %q", got, "3")
+	}
+	if got := r.Label(7); got != "7" {
+		t.Errorf("Label(7) = %q, want %q", got, "7")
+	}
+
+	all := slices.Values([]events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 7},
+		{Time: clock.MustParse("09:00:01.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:00:02.000"), EventID: 1, CompetitorID: 5},
+		{Time: clock.MustParse("09:00:03.000"), EventID: 3, CompetitorID: 7},
+	})
+	if got := r.Unknown(all); !reflect.DeepEqual(got, []int{5, 7}) {
+		t.Errorf("Unknown() = %v, want [5 7]", got)
+	}
+
+	var empty *Roster
+	if got := empty.Label(1); got != "1" || empty.Len() != 0 {
+		t.Errorf("nil roster Label(1) = %q, Len() = %d, want \"1\", 0", got, empty.Len())
+	}
+
+	if _, err := New([]Competitor{{ID: 1}, {ID: 1}}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("New() error = %v, want %v", err, ErrDuplicate)
+	}
+}
@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/events"
+	"slices"
+	"sort"
+)
+
+// Live follows a race while its events arrive. Every incoming event updates the
+// state of its competitor and yields the outgoing events it makes certain, so a
+// missed start is reported as soon as any event passes the closed start window.
+//
+// Events are expected in chronological order, as the timekeeping writes them.
+// The start mode schedules a competitor until a draw replaces the planned slot.
+// Relay legs after the first are drawn to the handover of the previous leg.
+type Live struct {
+	config   config.Race
+	store    *events.EventStore
+	referees map[int]*referee
+	machines map[int]*stateMachine
+	// draws are handovers to relay legs not registered yet
+	draws map[int]events.Event
+}
+
+func NewLive(config config.Race) *Live {
+	return &Live{
+		config:   config,
+		store:    &events.EventStore{},
+		referees: make(map[int]*referee),
+		machines: make(map[int]*stateMachine),
+		draws:    make(map[int]events.Event),
+	}
+}
+
+// Apply takes the next incoming event and returns it with the outgoing events it
+// caused, in time order
+func (l *Live) Apply(event events.Event) []events.Event {
+	var result []events.Event
+	for _, competitorID := range l.competitorIDs() {
+		result = append(result, l.referees[competitorID].expire(event.Time)...)
+	}
+
+	r, ok := l.referees[event.CompetitorID]
+	if !ok {
+		r = newReferee(l.config, event.CompetitorID, []events.Event{event})
+		l.referees[event.CompetitorID] = r
+		_, scheduled := scheduledStart(l.config, event.CompetitorID, []events.Event{event})
+		l.machines[event.CompetitorID] = &stateMachine{scheduled: scheduled, leg: laterLeg(l.config, event.CompetitorID)}
+	}
+	if event.EventID == events.StartTimeDrawn {
+		l.machines[event.CompetitorID].unschedule()
+	}
+	result = append(result, event)
+	result = append(result, r.observe(event)...)
+	result = l.record(result)
+
+	if draw, ok := l.draws[event.CompetitorID]; ok {
+		delete(l.draws, event.CompetitorID)
+		l.draw(draw)
+	}
+	if event.EventID == events.HandedOver {
+		l.handOver(event)
+	}
+	return result
+}
+
+// handOver draws the next leg of the relay to the handover time like HandOver does
+// for the reports. The draw only starts the window of the leg, it is not recorded.
+func (l *Live) handOver(event events.Event) {
+	team, leg, ok := l.config.Leg(event.CompetitorID)
+	if !ok || leg >= len(team.Members) {
+		return
+	}
+	next := team.Members[leg]
+	draw := events.Event{Time: event.Time, EventID: events.StartTimeDrawn, CompetitorID: next, StartTime: event.Time}
+	if _, ok := l.referees[next]; !ok {
+		l.draws[next] = draw
+		return
+	}
+	l.draw(draw)
+}
+
+func (l *Live) draw(draw events.Event) {
+	l.referees[draw.CompetitorID].observe(draw)
+	l.machines[draw.CompetitorID].apply(draw)
+}
+
+// Close ends the input and returns the disqualifications of the competitors who
+// never started
+func (l *Live) Close() []events.Event {
+	var result []events.Event
+	for _, competitorID := range l.competitorIDs() {
+		result = append(result, l.referees[competitorID].close()...)
+	}
+	return l.record(result)
+}
+
+// State is where a competitor is in the race after the events applied so far
+func (l *Live) State(competitorID int) State {
+	if m, ok := l.machines[competitorID]; ok {
+		return m.state
+	}
+	return Unregistered
+}
+
+// Store holds every event applied so far with the outgoing ones, ready for the reports
+func (l *Live) Store() *events.EventStore {
+	return l.store
+}
+
+// record sorts the events by time, applies them to the state machines and the store
+func (l *Live) record(result []events.Event) []events.Event {
+	slices.SortStableFunc(result, func(a, b events.Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	for _, event := range result {
+		l.machines[event.CompetitorID].apply(event)
+		l.store.Insert(event)
+	}
+	return result
+}
+
+func (l *Live) competitorIDs() []int {
+	competitorIDs := make([]int, 0, len(l.referees))
+	for competitorID := range l.referees {
+		competitorIDs = append(competitorIDs, competitorID)
+	}
+	sort.Ints(competitorIDs)
+	return competitorIDs
+}
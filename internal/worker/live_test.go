@@ -0,0 +1,150 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestLive(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3651, PenaltyLen: 50, StartDelta: "00:00:30"}
+	incoming := []events.Event{
+		{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:06:00.000"), EventID: 1, CompetitorID: 2},
+		{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+		{Time: clock.MustParse("09:15:01.000"), EventID: 2, CompetitorID: 2, StartTime: clock.MustParse("09:31:00.000")},
+		{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("09:45:00.000"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("10:00:00.000"), EventID: 10, CompetitorID: 1},
+	}
+
+	type step struct {
+		emitted []events.Event
+		state   State
+	}
+
+	live := NewLive(raceConfig)
+	var got []step
+	for _, event := range incoming {
+		emitted := live.Apply(event)
+		got = append(got, step{emitted: emitted, state: live.State(event.CompetitorID)})
+	}
+
+	disqualified := events.Event{Time: clock.MustParse("09:31:30.000"), EventID: 32, CompetitorID: 2}
+	finished := events.Event{Time: clock.MustParse("10:00:00.000"), EventID: 33, CompetitorID: 1}
+	want := []step{
+		{emitted: incoming[0:1], state: Registered},
+		{emitted: incoming[1:2], state: Registered},
+		{emitted: incoming[2:3], state: Drawn},
+		{emitted: incoming[3:4], state: Drawn},
+		{emitted: incoming[4:5], state: Racing},
+		{emitted: []events.Event{disqualified, incoming[5]}, state: Racing},
+		{emitted: []events.Event{incoming[6], finished}, state: Finished},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+	if state := live.State(2); state != Disqualified {
+		t.Errorf("State(2) = %s, want %s", state, Disqualified)
+	}
+	if closed := live.Close(); len(closed) != 0 {
+		t.Errorf("Close() = %v, want nothing left", closed)
+	}
+
+	batch := &events.EventStore{}
+	for _, event := range incoming {
+		batch.Insert(event)
+	}
+	GenerateOutgoing(raceConfig, batch)
+	if got, want := slices.Collect(live.Store().All()), slices.Collect(batch.All()); !slices.Equal(got, want) {
+		t.Errorf("Store() = %v, want the batch timeline %v", got, want)
+	}
+}
+
+func TestLiveDrawReplacesSchedule(t *testing.T) {
+	raceConfig := config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 150, Start: "10:00:00.000", StartDelta: "00:00:30",
+		StartMode: config.StartIndividual, StartInterval: "00:01:00"}
+	incoming := []events.Event{
+		{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 3},
+		{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 3, StartTime: clock.MustParse("10:05:00.000")},
+		{Time: clock.MustParse("10:05:10.000"), EventID: 4, CompetitorID: 3},
+		{Time: clock.MustParse("10:20:00.000"), EventID: 10, CompetitorID: 3},
+	}
+
+	live := NewLive(raceConfig)
+	batch := &events.EventStore{}
+	for _, event := range incoming {
+		live.Apply(event)
+		batch.Insert(event)
+	}
+	live.Close()
+	GenerateOutgoing(raceConfig, batch)
+
+	if got, want := slices.Collect(live.Store().All()), slices.Collect(batch.All()); !slices.Equal(got, want) {
+		t.Errorf("Store() = %v, want the batch timeline %v", got, want)
+	}
+	report, err := ProcessCompetitor(raceConfig, 3, batch.ByCompetitor()[3])
+	if err != nil {
+		t.Fatalf("ProcessCompetitor() error = %v", err)
+	}
+	if state := live.State(3); state != Finished || report.Status != "Finished" {
+		t.Errorf("State() = %s and report status %s, want both finished", state, report.Status)
+	}
+	if violations := live.machines[3].violations; !slices.Equal(violations, report.Violations) {
+		t.Errorf("violations = %v, want the batch ones %v", violations, report.Violations)
+	}
+}
+
+func TestLiveClose(t *testing.T) {
+	raceConfig := config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 150, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartMass}
+
+	live := NewLive(raceConfig)
+	live.Apply(events.Event{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1})
+	if state := live.State(1); state != Drawn {
+		t.Errorf("State() of a scheduled competitor = %s, want %s", state, Drawn)
+	}
+
+	want := []events.Event{{Time: clock.MustParse("10:00:30.000"), EventID: 32, CompetitorID: 1}}
+	if got := live.Close(); !slices.Equal(got, want) {
+		t.Errorf("Close() = %v, want %v", got, want)
+	}
+	if got := live.Close(); len(got) != 0 {
+		t.Errorf("second Close() = %v, want nothing", got)
+	}
+}
+
+func TestLiveRelay(t *testing.T) {
+	raceConfig := config.Race{
+		Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartMass,
+		Teams: []config.Team{{Name: "Blue", Members: []int{1, 2}}},
+	}
+	live := NewLive(raceConfig)
+	for _, event := range []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:00:01.000"), EventID: 1, CompetitorID: 2},
+		{Time: clock.MustParse("10:00:02.000"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("10:09:30.000"), EventID: 3, CompetitorID: 2},
+		{Time: clock.MustParse("10:10:00.000"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("10:10:03.000"), EventID: 12, CompetitorID: 1},
+	} {
+		live.Apply(event)
+	}
+	if state := live.State(2); state != OnStartLine {
+		t.Errorf("State(2) after the handover = %s, want %s", state, OnStartLine)
+	}
+
+	late := events.Event{Time: clock.MustParse("10:10:40.000"), EventID: 4, CompetitorID: 2}
+	disqualified := events.Event{Time: clock.MustParse("10:10:33.000"), EventID: 32, CompetitorID: 2}
+	if emitted := live.Apply(late); !reflect.DeepEqual(emitted, []events.Event{disqualified, late}) {
+		t.Errorf("Apply() = %v, want %v", emitted, []events.Event{disqualified, late})
+	}
+	if state := live.State(2); state != Disqualified {
+		t.Errorf("State(2) = %s, want %s", state, Disqualified)
+	}
+	if m := live.machines[2]; len(m.violations) != 0 {
+		t.Errorf("violations of leg 2 = %v, want none", m.violations)
+	}
+}
@@ -2,84 +2,188 @@ package worker
 
 import (
 	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
 	"CompetitionLogger/pkg/events"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 )
 
 type CompetitorReport struct {
 	CompetitorID int
+	Place        int
 	Status       string
-	TotalTime    string
+	TotalTime    time.Duration
 	Laps         []LapInfo
 	Penalty      PenaltyInfo
-	HitsShots    string
+	Penalties    []PenaltyVisit
+	Shooting     []FiringVisit
+	// TimePenalty marks the time penalty mode, where AddedTime for the misses
+	// is part of TotalTime instead of penalty loops
+	TimePenalty    bool
+	AddedTime      time.Duration
+	DisqualifiedAt clock.Time
+	// Reason is why the competitor was disqualified or could not continue
+	Reason     string
+	Violations []Violation
+}
+
+// Consistent tells whether the competitor's events form a possible race
+func (r CompetitorReport) Consistent() bool {
+	return len(r.Violations) == 0
 }
 
 type LapInfo struct {
-	Time  string
+	Time  time.Duration
 	Speed float64
 }
 
 type PenaltyInfo struct {
-	Time  string
+	Time  time.Duration
 	Speed float64
 }
 
-func ProcessCompetitor(config config.Race, competitorID int, events []events.Event) CompetitorReport {
+// PenaltyVisit is a single pass through the penalty laps, caused by the misses
+// on the firing range visited before it
+type PenaltyVisit struct {
+	FiringRange int
+	Misses      int
+	Time        time.Duration
+	Speed       float64
+}
+
+func ProcessCompetitor(config config.Race, competitorID int, competitorEvents []events.Event) (CompetitorReport, error) {
 	var reportTable CompetitorReport
 	reportTable.CompetitorID = competitorID
 	reportTable.Status = "NotStarted"
 
-	var plannedStart, actualStart, finishTime, lastEventTime string
-	var lapTimes []string
-	var penaltyStart, penaltyEnd string
-	hits := 0
-	firingLineVisits := 0
+	var plannedStart, actualStart, finishTime, lastEventTime clock.Time
+	var lapTimes []clock.Time
+	var penaltyStart clock.Time
+	var penalty PenaltyVisit
+	var window startWindow
+	machine := stateMachine{leg: laterLeg(config, competitorID)}
+	hasWindow := false
+	var rangeArrival clock.Time
+
+	// A pursuit competitor without a handicap still gets a full row, the error
+	// is returned with it
+	var handicapErr error
+	if !drawn(competitorEvents) {
+		handicapErr = config.CheckHandicap(competitorID)
+		if handicapErr != nil {
+			reportTable.Reason = "no handicap"
+		}
+	}
+	if start, ok := scheduledStart(config, competitorID, competitorEvents); ok {
+		plannedStart = start
+		window, hasWindow = newStartWindow(plannedStart, config.StartDelta)
+		machine.scheduled = true
+	}
 
-	for _, event := range events {
+	for _, event := range competitorEvents {
+		machine.apply(event)
 		switch event.EventID {
 		case 2:
-			plannedStart = event.ExtraParams
+			if event.StartTime.IsZero() {
+				return reportTable, fmt.Errorf("competitor %d: start time drawn at %s is missing", competitorID, event.Time)
+			}
+			plannedStart = event.StartTime
+			window, hasWindow = newStartWindow(plannedStart, config.StartDelta)
 			reportTable.Status = "NotStarted"
 		case 4:
 			actualStart = event.Time
 			reportTable.Status = "Started"
 		case 5:
-			firingLineVisits++
+			rangeArrival = event.Time
+			visit := FiringVisit{
+				FiringRange: event.FiringRange,
+				Lap:         len(lapTimes) + 1,
+				Targets:     config.Targets(),
+				Shots:       config.Shots(),
+				Misses:      config.Targets(),
+			}
+			if stage, ok := config.Stage(len(reportTable.Shooting)); ok {
+				visit.Position, visit.Lap, visit.Shots = stage.Position, stage.Lap, stage.Shots
+			}
+			visit.MaxShots = visit.Shots + config.SpareRounds
+			reportTable.Shooting = append(reportTable.Shooting, visit)
 		case 6:
-			hits++
+			if len(reportTable.Shooting) == 0 {
+				break
+			}
+			visit := &reportTable.Shooting[len(reportTable.Shooting)-1]
+			// A target falls once, a repeated hit on it is a duplicate record
+			if visit.Hit(event.Target) {
+				break
+			}
+			visit.Hits = append(visit.Hits, event.Target)
+			visit.Shots = max(visit.Shots, len(visit.Hits))
+			if visit.Misses > 0 {
+				visit.Misses--
+			}
+		case 7:
+			if len(reportTable.Shooting) == 0 || rangeArrival.IsZero() {
+				break
+			}
+			visit := &reportTable.Shooting[len(reportTable.Shooting)-1]
+			visit.Time = event.Time.Sub(rangeArrival)
+			rangeArrival = clock.Time{}
 		case 8:
 			penaltyStart = event.Time
+			penalty = PenaltyVisit{}
+			if len(reportTable.Shooting) > 0 {
+				visit := reportTable.Shooting[len(reportTable.Shooting)-1]
+				penalty.FiringRange, penalty.Misses = visit.FiringRange, visit.Misses
+			}
 		case 9:
-			penaltyEnd = event.Time
+			if penaltyStart.IsZero() {
+				break
+			}
+			penalty.Time = event.Time.Sub(penaltyStart)
+			if penalty.Time > 0 {
+				penalty.Speed = float64(penalty.Misses*config.PenaltyLen) / penalty.Time.Seconds()
+			}
+			reportTable.Penalties = append(reportTable.Penalties, penalty)
+			penaltyStart = clock.Time{}
 		case 10:
 			lapTimes = append(lapTimes, event.Time)
 		case 11:
 			reportTable.Status = "NotFinished"
+			reportTable.Reason = event.Comment
 			lastEventTime = event.Time
 		case 32:
-			reportTable.Status = "NotFinished"
-			lastEventTime = event.Time
+			reportTable.Status = "NotStarted"
+			reportTable.DisqualifiedAt = event.Time
+			reportTable.Reason = "disqualified"
 		case 33:
 			finishTime = event.Time
 			reportTable.Status = "Finished"
 		}
 	}
 
-	shots := firingLineVisits * 5
+	if hasWindow {
+		if disqualifiedAt, reason, ok := window.check(actualStart); !ok {
+			reportTable.Status = "NotStarted"
+			reportTable.DisqualifiedAt = disqualifiedAt
+			reportTable.Reason = reason
+		}
+	}
+
+	reportTable.Violations = machine.violations
 
-	if reportTable.Status == "NotStarted" {
-		reportTable.TotalTime = "00:00:00.000"
-	} else if reportTable.Status == "NotFinished" {
-		reportTable.TotalTime = subtractTimes(lastEventTime, plannedStart)
+	if reportTable.Status == "NotFinished" {
+		reportTable.TotalTime = elapsed(plannedStart, lastEventTime)
 	} else if reportTable.Status == "Finished" {
-		reportTable.TotalTime = subtractTimes(finishTime, plannedStart)
-	} else {
-		if actualStart != "" && lastEventTime != "" {
-			reportTable.TotalTime = subtractTimes(lastEventTime, plannedStart)
+		reportTable.TotalTime = elapsed(plannedStart, finishTime)
+	}
+
+	if config.TimePenalty() {
+		reportTable.TimePenalty = true
+		for _, visit := range reportTable.Shooting {
+			reportTable.AddedTime += time.Duration(visit.Misses) * config.PerMiss()
+		}
+		if reportTable.TotalTime > 0 {
+			reportTable.TotalTime += reportTable.AddedTime
 		}
 	}
 
@@ -90,78 +194,30 @@ func ProcessCompetitor(config config.Race, competitorID int, events []events.Eve
 			if i > 0 {
 				start = lapTimes[i-1]
 			}
-			lapInfo.Time = subtractTimes(lapTimes[i], start)
-			lapSeconds := TimeToSeconds(lapInfo.Time)
-			if lapSeconds > 0 {
-				lapInfo.Speed = float64(config.LapLen) / lapSeconds
+			lapInfo.Time = elapsed(start, lapTimes[i])
+			if lapInfo.Time > 0 {
+				lapInfo.Speed = float64(config.LapLen) / lapInfo.Time.Seconds()
 			}
 		}
 		reportTable.Laps = append(reportTable.Laps, lapInfo)
 	}
 
-	if penaltyStart != "" && penaltyEnd != "" {
-		reportTable.Penalty.Time = subtractTimes(penaltyEnd, penaltyStart)
-		penaltySeconds := TimeToSeconds(reportTable.Penalty.Time)
-		if penaltySeconds > 0 {
-			misses := shots - hits
-			penaltyDistance := misses * config.PenaltyLen
-			reportTable.Penalty.Speed = float64(penaltyDistance) / penaltySeconds
-		}
-	}
-
-	reportTable.HitsShots = fmt.Sprintf("%d/%d", hits, shots)
-
-	return reportTable
-}
-
-func subtractTimes(t2, t1 string) string {
-	if t1 == "" || t2 == "" {
-		return "00:00:00.000"
+	penaltyDistance := 0
+	for _, visit := range reportTable.Penalties {
+		reportTable.Penalty.Time += visit.Time
+		penaltyDistance += visit.Misses * config.PenaltyLen
 	}
-	layout := "15:04:05.000"
-	time1, err := time.Parse(layout, t1)
-	if err != nil {
-		return "00:00:00.000"
+	if reportTable.Penalty.Time > 0 {
+		reportTable.Penalty.Speed = float64(penaltyDistance) / reportTable.Penalty.Time.Seconds()
 	}
 
-	time2, err := time.Parse(layout, t2)
-	if err != nil {
-		return "00:00:00.000"
-	}
-
-	duration := time2.Sub(time1)
-	if duration < 0 {
-		return "00:00:00.000"
-	}
-	return formatDuration(duration)
+	return reportTable, handicapErr
 }
 
-func TimeToSeconds(timeStr string) float64 {
-	hoursMinutesSeconds := strings.Split(timeStr, ":")
-	if len(hoursMinutesSeconds) != 3 {
-		return 0
-	}
-
-	secondsMilliseconds := strings.Split(hoursMinutesSeconds[2], ".")
-	if len(secondsMilliseconds) != 2 {
+// elapsed is the time from start to end, zero if any of them is unknown
+func elapsed(start, end clock.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
 		return 0
 	}
-
-	hours, _ := strconv.Atoi(hoursMinutesSeconds[0])
-	minutes, _ := strconv.Atoi(hoursMinutesSeconds[1])
-	seconds, _ := strconv.Atoi(secondsMilliseconds[0])
-	milliseconds, _ := strconv.Atoi(secondsMilliseconds[1])
-	return float64(hours*3600+minutes*60+seconds) + float64(milliseconds)/1000.0
-}
-
-func formatDuration(d time.Duration) string {
-	if d < 0 {
-		return "00:00:00.000"
-	}
-
-	hours := int(d / time.Hour)
-	minutes := int(d/time.Minute) % 60
-	seconds := int(d/time.Second) % 60
-	milliseconds := int(d/time.Millisecond) % 1000
-	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, milliseconds)
+	return end.Sub(start)
 }
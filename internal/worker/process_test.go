@@ -2,146 +2,14 @@ package worker
 
 import (
 	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
 	"CompetitionLogger/pkg/events"
 	"math"
+	"reflect"
 	"testing"
 	"time"
 )
 
-func TestSubtractTimes(t *testing.T) {
-	type content struct {
-		name     string
-		t1       string
-		t2       string
-		expected string
-	}
-	tests := []content{
-		{
-			name:     "normal subtraction",
-			t1:       "09:30:00.000",
-			t2:       "09:59:03.872",
-			expected: "00:29:03.872",
-		},
-		{
-			name:     "empty t1",
-			t1:       "",
-			t2:       "09:59:03.872",
-			expected: "00:00:00.000",
-		},
-		{
-			name:     "empty t2",
-			t1:       "09:30:00.000",
-			t2:       "",
-			expected: "00:00:00.000",
-		},
-		{
-			name:     "negative duration",
-			t1:       "09:59:03.872",
-			t2:       "09:30:00.000",
-			expected: "00:00:00.000",
-		},
-		{
-			name:     "same time",
-			t1:       "09:30:00.000",
-			t2:       "09:30:00.000",
-			expected: "00:00:00.000",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := subtractTimes(tt.t2, tt.t1)
-			if result != tt.expected {
-				t.Errorf("subtractTimes(%q, %q) = %q, want %q", tt.t2, tt.t1, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestTimeToSeconds(t *testing.T) {
-	type content struct {
-		name     string
-		timeStr  string
-		expected float64
-	}
-	tests := []content{
-		{
-			name:     "normal time",
-			timeStr:  "00:29:03.872",
-			expected: 1743.872,
-		},
-		{
-			name:     "zero time",
-			timeStr:  "00:00:00.000",
-			expected: 0.0,
-		},
-		{
-			name:     "only milliseconds",
-			timeStr:  "00:00:00.500",
-			expected: 0.5,
-		},
-		{
-			name:     "invalid format",
-			timeStr:  "invalid",
-			expected: 0.0,
-		},
-		{
-			name:     "missing milliseconds",
-			timeStr:  "00:00:00",
-			expected: 0.0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := TimeToSeconds(tt.timeStr)
-			if math.Abs(result-tt.expected) > 0.001 {
-				t.Errorf("timeToSeconds(%q) = %v, want %v", tt.timeStr, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestFormatDuration(t *testing.T) {
-	type content struct {
-		name     string
-		duration time.Duration
-		expected string
-	}
-
-	tests := []content{
-		{
-			name:     "normal duration",
-			duration: 1743872 * time.Millisecond,
-			expected: "00:29:03.872",
-		},
-		{
-			name:     "zero duration",
-			duration: 0,
-			expected: "00:00:00.000",
-		},
-		{
-			name:     "negative duration",
-			duration: -1 * time.Second,
-			expected: "00:00:00.000",
-		},
-		{
-			name:     "only milliseconds",
-			duration: 500 * time.Millisecond,
-			expected: "00:00:00.500",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := formatDuration(tt.duration)
-			if result != tt.expected {
-				t.Errorf("formatDuration(%v) = %q, want %q", tt.duration, result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestProcessCompetitor(t *testing.T) {
 	racecConfig := config.Race{
 		Laps:        2,
@@ -160,62 +28,66 @@ func TestProcessCompetitor(t *testing.T) {
 		{
 			name: "not finished competitor",
 			events: []events.Event{
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
-				{Time: "09:30:01.005", EventID: 4, CompetitorID: 1},
-				{Time: "09:49:31.659", EventID: 5, CompetitorID: 1, ExtraParams: "1"},
-				{Time: "09:49:33.123", EventID: 6, CompetitorID: 1, ExtraParams: "1"},
-				{Time: "09:49:34.650", EventID: 6, CompetitorID: 1, ExtraParams: "2"},
-				{Time: "09:49:35.937", EventID: 6, CompetitorID: 1, ExtraParams: "4"},
-				{Time: "09:49:37.364", EventID: 6, CompetitorID: 1, ExtraParams: "5"},
-				{Time: "09:49:55.915", EventID: 8, CompetitorID: 1},
-				{Time: "09:51:48.391", EventID: 9, CompetitorID: 1},
-				{Time: "09:59:03.872", EventID: 10, CompetitorID: 1},
-				{Time: "09:59:03.872", EventID: 11, CompetitorID: 1, ExtraParams: "Заблудился в лесу"},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 1},
+				{Time: clock.MustParse("09:49:31.659"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+				{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 1},
+				{Time: clock.MustParse("09:49:34.650"), EventID: 6, CompetitorID: 1, Target: 2},
+				{Time: clock.MustParse("09:49:35.937"), EventID: 6, CompetitorID: 1, Target: 4},
+				{Time: clock.MustParse("09:49:37.364"), EventID: 6, CompetitorID: 1, Target: 5},
+				{Time: clock.MustParse("09:49:55.915"), EventID: 8, CompetitorID: 1},
+				{Time: clock.MustParse("09:51:48.391"), EventID: 9, CompetitorID: 1},
+				{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+				{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 1, Comment: "Заблудился в лесу"},
 			},
 			expected: CompetitorReport{
 				CompetitorID: 1,
 				Status:       "NotFinished",
-				TotalTime:    "00:29:03.872",
+				TotalTime:    clock.MustParseDuration("00:29:03.872"),
 				Laps: []LapInfo{
-					{Time: "00:29:02.867", Speed: 3651.0 / 1742.867},
-					{Time: "", Speed: 0.0},
+					{Time: clock.MustParseDuration("00:29:02.867"), Speed: 3651.0 / 1742.867},
+					{Time: 0, Speed: 0.0},
 				},
 				Penalty: PenaltyInfo{
-					Time:  "00:01:52.476",
+					Time:  clock.MustParseDuration("00:01:52.476"),
 					Speed: 50.0 / 112.476,
 				},
-				HitsShots: "4/5",
+				Shooting: []FiringVisit{
+					{FiringRange: 1, Lap: 1, Hits: []int{1, 2, 4, 5}, Targets: 5, Shots: 5, MaxShots: 5, Misses: 1},
+				},
 			},
 		},
 		{
 			name: "not started competitor",
 			events: []events.Event{
-				{Time: "09:05:59.867", EventID: 1, CompetitorID: 2},
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 2, ExtraParams: "09:30:30.000"},
+				{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 2},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 2, StartTime: clock.MustParse("09:30:30.000")},
 			},
 			expected: CompetitorReport{
 				CompetitorID: 2,
 				Status:       "NotStarted",
-				TotalTime:    "00:00:00.000",
-				Laps:         []LapInfo{{Time: "", Speed: 0.0}, {Time: "", Speed: 0.0}},
-				Penalty:      PenaltyInfo{Time: "", Speed: 0.0},
-				HitsShots:    "0/0",
+				TotalTime:    0,
+				Laps:         []LapInfo{{Time: 0, Speed: 0.0}, {Time: 0, Speed: 0.0}},
+				Penalty:      PenaltyInfo{Time: 0, Speed: 0.0},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ProcessCompetitor(racecConfig, tt.expected.CompetitorID, tt.events)
+			result, err := ProcessCompetitor(racecConfig, tt.expected.CompetitorID, tt.events)
+			if err != nil {
+				t.Fatalf("ProcessCompetitor() error = %v", err)
+			}
 
 			if result.Status != tt.expected.Status {
 				t.Errorf("Status = %q, want %q", result.Status, tt.expected.Status)
 			}
 			if result.TotalTime != tt.expected.TotalTime {
-				t.Errorf("TotalTime = %q, want %q", result.TotalTime, tt.expected.TotalTime)
+				t.Errorf("TotalTime = %v, want %v", result.TotalTime, tt.expected.TotalTime)
 			}
-			if result.HitsShots != tt.expected.HitsShots {
-				t.Errorf("HitsShots = %q, want %q", result.HitsShots, tt.expected.HitsShots)
+			if !reflect.DeepEqual(result.Shooting, tt.expected.Shooting) {
+				t.Errorf("Shooting = %v, want %v", result.Shooting, tt.expected.Shooting)
 			}
 
 			if len(result.Laps) != len(tt.expected.Laps) {
@@ -223,7 +95,7 @@ func TestProcessCompetitor(t *testing.T) {
 			}
 			for i, lap := range result.Laps {
 				if lap.Time != tt.expected.Laps[i].Time {
-					t.Errorf("Lap[%d].Time = %q, want %q", i, lap.Time, tt.expected.Laps[i].Time)
+					t.Errorf("Lap[%d].Time = %v, want %v", i, lap.Time, tt.expected.Laps[i].Time)
 				}
 				if math.Abs(lap.Speed-tt.expected.Laps[i].Speed) > 0.001 {
 					t.Errorf("Lap[%d].Speed = %v, want %v", i, lap.Speed, tt.expected.Laps[i].Speed)
@@ -231,7 +103,7 @@ func TestProcessCompetitor(t *testing.T) {
 			}
 
 			if result.Penalty.Time != tt.expected.Penalty.Time {
-				t.Errorf("Penalty.Time = %q, want %q", result.Penalty.Time, tt.expected.Penalty.Time)
+				t.Errorf("Penalty.Time = %v, want %v", result.Penalty.Time, tt.expected.Penalty.Time)
 			}
 			if math.Abs(result.Penalty.Speed-tt.expected.Penalty.Speed) > 0.001 {
 				t.Errorf("Penalty.Speed = %v, want %v", result.Penalty.Speed, tt.expected.Penalty.Speed)
@@ -239,3 +111,204 @@ func TestProcessCompetitor(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessCompetitorStartWindow(t *testing.T) {
+	raceConfig := config.Race{
+		Laps:       2,
+		LapLen:     3651,
+		PenaltyLen: 50,
+		StartDelta: "00:00:30",
+	}
+
+	type content struct {
+		name           string
+		events         []events.Event
+		status         string
+		disqualifiedAt string
+		reason         string
+	}
+
+	tests := []content{
+		{
+			name: "started in the window",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:30:29.999"), EventID: 4, CompetitorID: 1},
+			},
+			status: "Started",
+		},
+		{
+			name: "started late",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:30:30.000"), EventID: 32, CompetitorID: 1},
+				{Time: clock.MustParse("09:33:00.000"), EventID: 4, CompetitorID: 1},
+			},
+			status:         "NotStarted",
+			disqualifiedAt: "09:30:30.000",
+			reason:         "started at 09:33:00.000 after the start window closed at 09:30:30.000",
+		},
+		{
+			name: "started early",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:29:58.000"), EventID: 4, CompetitorID: 1},
+			},
+			status:         "NotStarted",
+			disqualifiedAt: "09:29:58.000",
+			reason:         "started at 09:29:58.000 before the start window opened at 09:30:00.000",
+		},
+		{
+			name: "never started",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00")},
+			},
+			status:         "NotStarted",
+			disqualifiedAt: "09:30:30.000",
+			reason:         "did not start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessCompetitor(raceConfig, 1, tt.events)
+			if err != nil {
+				t.Fatalf("ProcessCompetitor() error = %v", err)
+			}
+			if result.Status != tt.status {
+				t.Errorf("Status = %q, want %q", result.Status, tt.status)
+			}
+			if result.DisqualifiedAt.String() != tt.disqualifiedAt {
+				t.Errorf("DisqualifiedAt = %q, want %q", result.DisqualifiedAt, tt.disqualifiedAt)
+			}
+			if result.Reason != tt.reason {
+				t.Errorf("Reason = %q, want %q", result.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestProcessCompetitorMissingDraw(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3651, PenaltyLen: 50}
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1},
+	}
+
+	_, err := ProcessCompetitor(raceConfig, 1, competitorEvents)
+	if err == nil {
+		t.Errorf("ProcessCompetitor() error = nil, want missing start time")
+	}
+}
+
+func TestProcessCompetitorPenalties(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2}
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("10:00:00.000")},
+		{Time: clock.MustParse("10:00:01.000"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("10:08:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("10:08:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("10:08:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
+		{Time: clock.MustParse("10:08:03.000"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("10:08:04.000"), EventID: 6, CompetitorID: 1, Target: 4},
+		{Time: clock.MustParse("10:08:05.000"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:08:10.000"), EventID: 8, CompetitorID: 1},
+		{Time: clock.MustParse("10:09:00.000"), EventID: 9, CompetitorID: 1},
+		{Time: clock.MustParse("10:15:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 2},
+		{Time: clock.MustParse("10:15:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("10:15:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
+		{Time: clock.MustParse("10:15:03.000"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("10:15:04.000"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:15:10.000"), EventID: 8, CompetitorID: 1},
+		{Time: clock.MustParse("10:17:10.000"), EventID: 9, CompetitorID: 1},
+		{Time: clock.MustParse("10:20:00.000"), EventID: 10, CompetitorID: 1},
+	}
+
+	result, err := ProcessCompetitor(raceConfig, 1, competitorEvents)
+	if err != nil {
+		t.Fatalf("ProcessCompetitor() error = %v", err)
+	}
+
+	expected := []PenaltyVisit{
+		{FiringRange: 1, Misses: 1, Time: 50 * time.Second, Speed: 150.0 / 50},
+		{FiringRange: 2, Misses: 2, Time: 2 * time.Minute, Speed: 300.0 / 120},
+	}
+	if len(result.Penalties) != len(expected) {
+		t.Fatalf("Penalties = %v, want %v", result.Penalties, expected)
+	}
+	for i, visit := range result.Penalties {
+		if visit.FiringRange != expected[i].FiringRange || visit.Misses != expected[i].Misses || visit.Time != expected[i].Time {
+			t.Errorf("Penalties[%d] = %v, want %v", i, visit, expected[i])
+		}
+		if math.Abs(visit.Speed-expected[i].Speed) > 0.001 {
+			t.Errorf("Penalties[%d].Speed = %v, want %v", i, visit.Speed, expected[i].Speed)
+		}
+	}
+
+	if result.Penalty.Time != 170*time.Second {
+		t.Errorf("Penalty.Time = %v, want %v", result.Penalty.Time, 170*time.Second)
+	}
+	if math.Abs(result.Penalty.Speed-450.0/170) > 0.001 {
+		t.Errorf("Penalty.Speed = %v, want %v", result.Penalty.Speed, 450.0/170)
+	}
+}
+
+func TestProcessCompetitorTimePenalty(t *testing.T) {
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("10:00:00.000")},
+		{Time: clock.MustParse("10:00:00.000"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("10:08:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("10:08:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("10:08:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
+		{Time: clock.MustParse("10:08:03.000"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("10:08:05.000"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:20:00.000"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 1},
+	}
+
+	type content struct {
+		name      string
+		config    config.Race
+		addedTime time.Duration
+		totalTime time.Duration
+	}
+
+	tests := []content{
+		{
+			name:      "loop mode",
+			config:    config.Race{Laps: 1, LapLen: 3500, PenaltyLen: 150},
+			totalTime: 20 * time.Minute,
+		},
+		{
+			name:      "default penalty per miss",
+			config:    config.Race{Laps: 1, LapLen: 3500, PenaltyMode: config.PenaltyTime},
+			addedTime: 2 * time.Minute,
+			totalTime: 22 * time.Minute,
+		},
+		{
+			name:      "custom penalty per miss",
+			config:    config.Race{Laps: 1, LapLen: 3500, PenaltyMode: config.PenaltyTime, PenaltyPerMiss: "00:00:45.000"},
+			addedTime: 90 * time.Second,
+			totalTime: 21*time.Minute + 30*time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessCompetitor(tt.config, 1, competitorEvents)
+			if err != nil {
+				t.Fatalf("ProcessCompetitor() error = %v", err)
+			}
+			if result.TimePenalty != tt.config.TimePenalty() {
+				t.Errorf("TimePenalty = %v, want %v", result.TimePenalty, tt.config.TimePenalty())
+			}
+			if result.AddedTime != tt.addedTime {
+				t.Errorf("AddedTime = %v, want %v", result.AddedTime, tt.addedTime)
+			}
+			if result.TotalTime != tt.totalTime {
+				t.Errorf("TotalTime = %v, want %v", result.TotalTime, tt.totalTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package worker
+
+import "sort"
+
+// statusOrder is the order of the status groups in the final report
+var statusOrder = map[string]int{
+	"Finished":    0,
+	"Started":     1,
+	"NotFinished": 2,
+	"NotStarted":  3,
+}
+
+// Rank sorts reports for the final table: finished competitors by ascending
+// total time, then the Started, NotFinished and NotStarted groups. Finished
+// competitors get places, equal times share a place and the next one is skipped;
+// the rest stay unplaced with Place 0.
+func Rank(reports []CompetitorReport) {
+	sort.SliceStable(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		if a.Status == "Finished" && a.TotalTime != b.TotalTime {
+			return a.TotalTime < b.TotalTime
+		}
+		return a.CompetitorID < b.CompetitorID
+	})
+
+	for i := range reports {
+		reports[i].Place = 0
+		if reports[i].Status != "Finished" {
+			continue
+		}
+		reports[i].Place = i + 1
+		if i > 0 && reports[i-1].Status == "Finished" && reports[i-1].TotalTime == reports[i].TotalTime {
+			reports[i].Place = reports[i-1].Place
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	reports := []CompetitorReport{
+		{CompetitorID: 1, Status: "NotStarted"},
+		{CompetitorID: 2, Status: "Finished", TotalTime: clock.MustParseDuration("00:30:00.000")},
+		{CompetitorID: 3, Status: "NotFinished", TotalTime: clock.MustParseDuration("00:10:00.000")},
+		{CompetitorID: 4, Status: "Finished", TotalTime: clock.MustParseDuration("00:29:00.000")},
+		{CompetitorID: 5, Status: "Finished", TotalTime: clock.MustParseDuration("00:29:00.000")},
+		{CompetitorID: 6, Status: "Started"},
+		{CompetitorID: 7, Status: "Finished", TotalTime: clock.MustParseDuration("00:31:00.000")},
+	}
+
+	type content struct {
+		competitorID int
+		place        int
+	}
+	expected := []content{
+		{competitorID: 4, place: 1},
+		{competitorID: 5, place: 1},
+		{competitorID: 2, place: 3},
+		{competitorID: 7, place: 4},
+		{competitorID: 6, place: 0},
+		{competitorID: 3, place: 0},
+		{competitorID: 1, place: 0},
+	}
+
+	Rank(reports)
+
+	for i, want := range expected {
+		if reports[i].CompetitorID != want.competitorID || reports[i].Place != want.place {
+			t.Errorf("Rank()[%d] = competitor %d place %d, want competitor %d place %d",
+				i, reports[i].CompetitorID, reports[i].Place, want.competitorID, want.place)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"sort"
+)
+
+// referee derives the outgoing events of a competitor one incoming event at a time
+type referee struct {
+	competitorID int
+	config       config.Race
+	window       startWindow
+	hasWindow    bool
+	started      bool
+	done         bool
+	laps         int
+}
+
+// newReferee starts from the scheduled slot of the competitor if the start mode plans one
+func newReferee(config config.Race, competitorID int, competitorEvents []events.Event) *referee {
+	r := &referee{competitorID: competitorID, config: config}
+	if start, ok := scheduledStart(config, competitorID, competitorEvents); ok {
+		r.window, r.hasWindow = newStartWindow(start, config.StartDelta)
+	}
+	return r
+}
+
+func (r *referee) emit(eventID int, at clock.Time) []events.Event {
+	r.done = true
+	return []events.Event{{Time: at, EventID: eventID, CompetitorID: r.competitorID}}
+}
+
+// expire disqualifies the competitor once the clock passed a closed start window
+// without a start
+func (r *referee) expire(now clock.Time) []events.Event {
+	if r.done || !r.hasWindow || r.started || !r.window.missed(now) {
+		return nil
+	}
+	return r.emit(events.Disqualified, r.window.close)
+}
+
+// observe takes the next event of the competitor
+func (r *referee) observe(event events.Event) []events.Event {
+	if r.done {
+		return nil
+	}
+
+	switch event.EventID {
+	case events.StartTimeDrawn:
+		r.window, r.hasWindow = newStartWindow(event.StartTime, r.config.StartDelta)
+	case events.Started:
+		r.started = true
+		if r.hasWindow {
+			if disqualifiedAt, _, ok := r.window.check(event.Time); !ok {
+				return r.emit(events.Disqualified, disqualifiedAt)
+			}
+		}
+	case events.EndedMainLap:
+		r.laps++
+		if r.laps == r.config.Laps {
+			return r.emit(events.Finished, event.Time)
+		}
+	case events.CannotContinue:
+		r.done = true
+	}
+	return nil
+}
+
+// close ends the input, disqualifying a competitor who never started in the window
+func (r *referee) close() []events.Event {
+	if r.done || !r.hasWindow || r.started {
+		return nil
+	}
+	return r.emit(events.Disqualified, r.window.close)
+}
+
+// Outgoing derives the events generated by the system for a single competitor:
+// Disqualified if the competitor does not start within the drawn or scheduled
+// slot and Finished once config.Laps main laps are completed.
+func Outgoing(config config.Race, competitorID int, competitorEvents []events.Event) []events.Event {
+	r := newReferee(config, competitorID, competitorEvents)
+	var generated []events.Event
+	for _, event := range competitorEvents {
+		generated = append(generated, r.expire(event.Time)...)
+		generated = append(generated, r.observe(event)...)
+	}
+	return append(generated, r.close()...)
+}
+
+// GenerateOutgoing inserts the outgoing events of every competitor into the store's timeline
+func GenerateOutgoing(config config.Race, store *events.EventStore) {
+	byCompetitor := HandOver(config, store.ByCompetitor())
+	competitorIDs := make([]int, 0, len(byCompetitor))
+	for competitorID := range byCompetitor {
+		competitorIDs = append(competitorIDs, competitorID)
+	}
+	sort.Ints(competitorIDs)
+
+	for _, competitorID := range competitorIDs {
+		for _, event := range Outgoing(config, competitorID, byCompetitor[competitorID]) {
+			store.Insert(event)
+		}
+	}
+}
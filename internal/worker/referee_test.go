@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"reflect"
+	"testing"
+)
+
+func TestOutgoing(t *testing.T) {
+	raceConfig := config.Race{
+		Laps:       2,
+		LapLen:     3651,
+		PenaltyLen: 50,
+		StartDelta: "00:00:30",
+	}
+
+	type content struct {
+		name     string
+		events   []events.Event
+		expected []events.Event
+	}
+
+	tests := []content{
+		{
+			name: "finished competitor",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 1},
+				{Time: clock.MustParse("09:45:00.000"), EventID: 10, CompetitorID: 1},
+				{Time: clock.MustParse("10:00:00.000"), EventID: 10, CompetitorID: 1},
+			},
+			expected: []events.Event{
+				{Time: clock.MustParse("10:00:00.000"), EventID: 33, CompetitorID: 1},
+			},
+		},
+		{
+			name: "never started",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+			},
+			expected: []events.Event{
+				{Time: clock.MustParse("09:30:30.000"), EventID: 32, CompetitorID: 1},
+			},
+		},
+		{
+			name: "started after the slot",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:33:00.000"), EventID: 4, CompetitorID: 1},
+				{Time: clock.MustParse("09:45:00.000"), EventID: 10, CompetitorID: 1},
+				{Time: clock.MustParse("10:00:00.000"), EventID: 10, CompetitorID: 1},
+			},
+			expected: []events.Event{
+				{Time: clock.MustParse("09:30:30.000"), EventID: 32, CompetitorID: 1},
+			},
+		},
+		{
+			name: "started before the slot",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:29:59.000"), EventID: 4, CompetitorID: 1},
+			},
+			expected: []events.Event{
+				{Time: clock.MustParse("09:29:59.000"), EventID: 32, CompetitorID: 1},
+			},
+		},
+		{
+			name: "can't continue",
+			events: []events.Event{
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 1},
+				{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+				{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
+			},
+			expected: nil,
+		},
+		{
+			name: "only registered",
+			events: []events.Event{
+				{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Outgoing(raceConfig, 1, tt.events)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Outgoing() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// LegReport is the part of a relay skied by one athlete
+type LegReport struct {
+	Leg        int
+	Time       time.Duration
+	HandedOver clock.Time
+	Report     CompetitorReport
+}
+
+type TeamReport struct {
+	Team      string
+	Place     int
+	Status    string
+	TotalTime time.Duration
+	Legs      []LegReport
+}
+
+// ProcessRelay builds the report of a relay team. The first leg starts as an
+// individual competitor would, every next one at the handover of the previous
+// leg as if drawn to it. A finished leg runs until the handover, the last one
+// until the finish.
+func ProcessRelay(config config.Race, team config.Team, eventsByCompetitor map[int][]events.Event) (TeamReport, error) {
+	result := TeamReport{Team: team.Name, Status: "NotStarted"}
+	var errs []error
+
+	eventsByCompetitor = HandOver(config, eventsByCompetitor)
+	var legStart, handover clock.Time
+	for i, competitorID := range team.Members {
+		legEvents := eventsByCompetitor[competitorID]
+		if i == 0 {
+			legStart = firstLegStart(config, competitorID, legEvents)
+		} else {
+			legStart = handover
+		}
+
+		report, err := ProcessCompetitor(config, competitorID, legEvents)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("team %s leg %d: %w", team.Name, i+1, err))
+		}
+
+		leg := LegReport{Leg: i + 1, Report: report}
+		handover = handoverTime(legEvents)
+		if i == len(team.Members)-1 {
+			leg.Time = report.TotalTime
+		} else {
+			leg.HandedOver = handover
+			if report.Status == "Finished" {
+				if leg.Time = elapsed(legStart, handover); leg.Time > 0 {
+					leg.Time += report.AddedTime
+				}
+			}
+		}
+		result.Legs = append(result.Legs, leg)
+	}
+
+	result.Status = relayStatus(result.Legs)
+	if result.Status == "Finished" {
+		for _, leg := range result.Legs {
+			result.TotalTime += leg.Time
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// relayStatus follows the legs until the first one that is not finished and
+// handed over: a team is not started until its first athlete starts and does
+// not finish once an athlete drops out or is disqualified
+func relayStatus(legs []LegReport) string {
+	for i, leg := range legs {
+		last := i == len(legs)-1
+		switch leg.Report.Status {
+		case "NotFinished":
+			return "NotFinished"
+		case "NotStarted":
+			if i == 0 {
+				return "NotStarted"
+			}
+			if leg.Report.Reason != "" {
+				return "NotFinished"
+			}
+			return "Started"
+		case "Started":
+			return "Started"
+		case "Finished":
+			if last {
+				return "Finished"
+			}
+			if leg.HandedOver.IsZero() {
+				return "Started"
+			}
+		}
+	}
+	return "NotStarted"
+}
+
+// laterLeg tells whether a competitor skis a relay leg after the first, which
+// starts at the handover
+func laterLeg(config config.Race, competitorID int) bool {
+	_, leg, ok := config.Leg(competitorID)
+	return ok && leg > 1
+}
+
+// firstLegStart is the drawn or scheduled start of the first leg
+func firstLegStart(config config.Race, competitorID int, competitorEvents []events.Event) clock.Time {
+	for _, event := range competitorEvents {
+		if event.EventID == events.StartTimeDrawn {
+			return event.StartTime
+		}
+	}
+	start, _ := scheduledStart(config, competitorID, competitorEvents)
+	return start
+}
+
+// handoverTime is the time of the first handover, zero without one
+func handoverTime(competitorEvents []events.Event) clock.Time {
+	for _, event := range competitorEvents {
+		if event.EventID == events.HandedOver {
+			return event.Time
+		}
+	}
+	return clock.Time{}
+}
+
+// HandOver returns eventsByCompetitor with every relay leg after the first drawn
+// to the handover of the previous leg, so the leg is processed like a competitor
+// drawn to start then. Legs not handed over yet and individual competitors keep
+// their events.
+func HandOver(config config.Race, eventsByCompetitor map[int][]events.Event) map[int][]events.Event {
+	result := maps.Clone(eventsByCompetitor)
+	for _, team := range config.Teams {
+		for i := 1; i < len(team.Members); i++ {
+			competitorID := team.Members[i]
+			competitorEvents, ok := eventsByCompetitor[competitorID]
+			handover := handoverTime(eventsByCompetitor[team.Members[i-1]])
+			if !ok || handover.IsZero() {
+				continue
+			}
+			result[competitorID] = drawnAt(competitorEvents, competitorID, handover)
+		}
+	}
+	return result
+}
+
+// drawnAt returns a copy of competitorEvents with a draw to the handover time
+// placed before every event at or after it
+func drawnAt(competitorEvents []events.Event, competitorID int, handover clock.Time) []events.Event {
+	idx := sort.Search(len(competitorEvents), func(i int) bool {
+		return !competitorEvents[i].Time.Before(handover)
+	})
+	draw := events.Event{Time: handover, EventID: events.StartTimeDrawn, CompetitorID: competitorID, StartTime: handover}
+	return slices.Insert(slices.Clone(competitorEvents), idx, draw)
+}
+
+// RankTeams orders relay teams like Rank orders competitors, teams with equal
+// status and time keep the order of their names
+func RankTeams(teams []TeamReport) {
+	sort.SliceStable(teams, func(i, j int) bool {
+		a, b := teams[i], teams[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		if a.Status == "Finished" && a.TotalTime != b.TotalTime {
+			return a.TotalTime < b.TotalTime
+		}
+		return a.Team < b.Team
+	})
+
+	for i := range teams {
+		teams[i].Place = 0
+		if teams[i].Status != "Finished" {
+			continue
+		}
+		teams[i].Place = i + 1
+		if i > 0 && teams[i-1].Status == "Finished" && teams[i-1].TotalTime == teams[i].TotalTime {
+			teams[i].Place = teams[i-1].Place
+		}
+	}
+}
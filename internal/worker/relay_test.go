@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProcessRelay(t *testing.T) {
+	raceConfig := config.Race{
+		Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartMass,
+		Teams: []config.Team{{Name: "Blue", Members: []int{1, 2, 3}}},
+	}
+	leg := func(competitorID int, start, finish, handover string) []events.Event {
+		result := []events.Event{{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: competitorID}}
+		if start == "" {
+			return result
+		}
+		result = append(result, events.Event{Time: clock.MustParse(start), EventID: 4, CompetitorID: competitorID})
+		if finish == "" {
+			return result
+		}
+		result = append(result,
+			events.Event{Time: clock.MustParse(finish), EventID: 10, CompetitorID: competitorID},
+			events.Event{Time: clock.MustParse(finish), EventID: 33, CompetitorID: competitorID},
+		)
+		if handover != "" {
+			result = append(result, events.Event{Time: clock.MustParse(handover), EventID: 12, CompetitorID: competitorID})
+		}
+		return result
+	}
+	// onStartLine puts the athlete of a leg on the start line after the registration
+	onStartLine := func(legEvents []events.Event, at string) []events.Event {
+		event := events.Event{Time: clock.MustParse(at), EventID: 3, CompetitorID: legEvents[0].CompetitorID}
+		return slices.Insert(legEvents, 1, event)
+	}
+
+	type content struct {
+		name      string
+		events    map[int][]events.Event
+		status    string
+		totalTime time.Duration
+		legTimes  []time.Duration
+	}
+
+	tests := []content{
+		{
+			name: "finished",
+			events: map[int][]events.Event{
+				1: leg(1, "10:00:02.000", "10:10:00.000", "10:10:03.000"),
+				2: leg(2, "10:10:05.000", "10:21:00.000", "10:21:02.000"),
+				3: leg(3, "10:21:10.000", "10:30:00.000", ""),
+			},
+			status:    "Finished",
+			totalTime: 30 * time.Minute,
+			legTimes:  []time.Duration{10*time.Minute + 3*time.Second, 10*time.Minute + 59*time.Second, 8*time.Minute + 58*time.Second},
+		},
+		{
+			name: "on the start line before the handover",
+			events: map[int][]events.Event{
+				1: leg(1, "10:00:02.000", "10:10:00.000", "10:10:03.000"),
+				2: onStartLine(leg(2, "10:10:05.000", "10:21:00.000", "10:21:02.000"), "10:09:30.000"),
+				3: onStartLine(leg(3, "10:21:10.000", "10:30:00.000", ""), "10:20:00.000"),
+			},
+			status:    "Finished",
+			totalTime: 30 * time.Minute,
+			legTimes:  []time.Duration{10*time.Minute + 3*time.Second, 10*time.Minute + 59*time.Second, 8*time.Minute + 58*time.Second},
+		},
+		{
+			name: "waiting for the handover",
+			events: map[int][]events.Event{
+				1: leg(1, "10:00:02.000", "10:10:00.000", "10:10:03.000"),
+				2: leg(2, "10:10:05.000", "10:21:00.000", ""),
+				3: leg(3, "", "", ""),
+			},
+			status:   "Started",
+			legTimes: []time.Duration{10*time.Minute + 3*time.Second, 0, 0},
+		},
+		{
+			name: "late after the handover",
+			events: map[int][]events.Event{
+				1: leg(1, "10:00:02.000", "10:10:00.000", "10:10:03.000"),
+				2: leg(2, "10:10:40.000", "10:21:00.000", "10:21:02.000"),
+				3: leg(3, "", "", ""),
+			},
+			status:   "NotFinished",
+			legTimes: []time.Duration{10*time.Minute + 3*time.Second, 0, 0},
+		},
+		{
+			name: "first leg did not start",
+			events: map[int][]events.Event{
+				1: leg(1, "", "", ""),
+				2: leg(2, "", "", ""),
+				3: leg(3, "", "", ""),
+			},
+			status:   "NotStarted",
+			legTimes: []time.Duration{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessRelay(raceConfig, raceConfig.Teams[0], tt.events)
+			if err != nil {
+				t.Fatalf("ProcessRelay() error = %v", err)
+			}
+			if result.Status != tt.status || result.TotalTime != tt.totalTime {
+				t.Errorf("ProcessRelay() = %s in %v, want %s in %v", result.Status, result.TotalTime, tt.status, tt.totalTime)
+			}
+			if len(result.Legs) != len(tt.legTimes) {
+				t.Fatalf("Legs = %v, want %d legs", result.Legs, len(tt.legTimes))
+			}
+			for i, leg := range result.Legs {
+				if leg.Leg != i+1 || leg.Report.CompetitorID != raceConfig.Teams[0].Members[i] || leg.Time != tt.legTimes[i] {
+					t.Errorf("Legs[%d] = leg %d of %d in %v, want leg %d of %d in %v",
+						i, leg.Leg, leg.Report.CompetitorID, leg.Time, i+1, raceConfig.Teams[0].Members[i], tt.legTimes[i])
+				}
+				if tt.status == "Finished" && !leg.Report.Consistent() {
+					t.Errorf("Legs[%d] violations = %v, want none", i, leg.Report.Violations)
+				}
+			}
+		})
+	}
+}
+
+func TestRankTeams(t *testing.T) {
+	teams := []TeamReport{
+		{Team: "Red", Status: "NotFinished"},
+		{Team: "Green", Status: "Finished", TotalTime: 31 * time.Minute},
+		{Team: "Blue", Status: "Finished", TotalTime: 30 * time.Minute},
+		{Team: "Amber", Status: "Finished", TotalTime: 31 * time.Minute},
+	}
+	RankTeams(teams)
+
+	expected := []struct {
+		team  string
+		place int
+	}{{"Blue", 1}, {"Amber", 2}, {"Green", 2}, {"Red", 0}}
+	for i, want := range expected {
+		if teams[i].Team != want.team || teams[i].Place != want.place {
+			t.Errorf("RankTeams()[%d] = %s place %d, want %s place %d", i, teams[i].Team, teams[i].Place, want.team, want.place)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"slices"
+	"time"
+)
+
+// FiringVisit is a single stop of a competitor on a firing range
+type FiringVisit struct {
+	FiringRange int
+	// Position and Lap come from the configured stage of the visit, the lap is
+	// counted from the laps ended when no stage is configured
+	Position string
+	Lap      int
+	// Hits lists the targets hit, in the order they fell
+	Hits    []int
+	Targets int
+	// Shots are known to be fired: the loaded rounds of the stage, more when the
+	// hits prove spare rounds were used. Events do not record the shots themselves,
+	// so spare rounds that missed or felled the last target are not counted and
+	// MaxShots is the upper bound with every spare round fired.
+	Shots    int
+	MaxShots int
+	Misses   int
+	// Time is spent on the range, from arriving to leaving it
+	Time time.Duration
+}
+
+// Hit tells whether target was hit during the visit
+func (v FiringVisit) Hit(target int) bool {
+	return slices.Contains(v.Hits, target)
+}
+
+// Hits is the number of targets hit over every firing range visit
+func (r CompetitorReport) Hits() int {
+	hits := 0
+	for _, visit := range r.Shooting {
+		hits += len(visit.Hits)
+	}
+	return hits
+}
+
+// Shots is the number of shots fired over every firing range visit
+func (r CompetitorReport) Shots() int {
+	shots := 0
+	for _, visit := range r.Shooting {
+		shots += visit.Shots
+	}
+	return shots
+}
+
+// PositionStats is the shooting result over every visit in one position
+type PositionStats struct {
+	Position string
+	Hits     int
+	Shots    int
+}
+
+// ByPosition summarises the shooting for each position in the order they were
+// first shot in, visits without a known position are left out
+func (r CompetitorReport) ByPosition() []PositionStats {
+	var result []PositionStats
+	for _, visit := range r.Shooting {
+		if visit.Position == "" {
+			continue
+		}
+		idx := slices.IndexFunc(result, func(s PositionStats) bool {
+			return s.Position == visit.Position
+		})
+		if idx < 0 {
+			result = append(result, PositionStats{Position: visit.Position})
+			idx = len(result) - 1
+		}
+		result[idx].Hits += len(visit.Hits)
+		result[idx].Shots += visit.Shots
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShooting(t *testing.T) {
+	raceConfig := config.Race{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2}
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("10:00:00.000")},
+		{Time: clock.MustParse("10:00:01.000"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("10:08:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("10:08:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("10:08:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
+		{Time: clock.MustParse("10:08:03.500"), EventID: 6, CompetitorID: 1, Target: 4},
+		{Time: clock.MustParse("10:08:03.600"), EventID: 6, CompetitorID: 1, Target: 4},
+		{Time: clock.MustParse("10:08:04.000"), EventID: 6, CompetitorID: 1, Target: 5},
+		{Time: clock.MustParse("10:08:06.680"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:15:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 2},
+		{Time: clock.MustParse("10:15:09.000"), EventID: 7, CompetitorID: 1},
+	}
+
+	result, err := ProcessCompetitor(raceConfig, 1, competitorEvents)
+	if err != nil {
+		t.Fatalf("ProcessCompetitor() error = %v", err)
+	}
+
+	expected := []FiringVisit{
+		{FiringRange: 1, Lap: 1, Hits: []int{1, 2, 4, 5}, Targets: 5, Shots: 5, MaxShots: 5, Misses: 1, Time: 6680 * time.Millisecond},
+		{FiringRange: 2, Lap: 1, Targets: 5, Shots: 5, MaxShots: 5, Misses: 5, Time: 9 * time.Second},
+	}
+	if !reflect.DeepEqual(result.Shooting, expected) {
+		t.Errorf("Shooting = %v, want %v", result.Shooting, expected)
+	}
+	if result.Hits() != 4 || result.Shots() != 10 {
+		t.Errorf("Hits()/Shots() = %d/%d, want 4/10", result.Hits(), result.Shots())
+	}
+	if !result.Shooting[0].Hit(4) || result.Shooting[0].Hit(3) {
+		t.Errorf("Hit() does not match the hit targets %v", result.Shooting[0].Hits)
+	}
+}
+
+func TestShootingFormats(t *testing.T) {
+	visitEvents := func(targets ...int) []events.Event {
+		result := []events.Event{
+			{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+			{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("10:00:00.000")},
+			{Time: clock.MustParse("10:00:01.000"), EventID: 4, CompetitorID: 1},
+			{Time: clock.MustParse("10:08:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		}
+		for _, target := range targets {
+			result = append(result, events.Event{Time: clock.MustParse("10:08:10.000"), EventID: 6, CompetitorID: 1, Target: target})
+		}
+		result = append(result,
+			events.Event{Time: clock.MustParse("10:08:30.000"), EventID: 7, CompetitorID: 1},
+			events.Event{Time: clock.MustParse("10:08:40.000"), EventID: 8, CompetitorID: 1},
+			events.Event{Time: clock.MustParse("10:09:40.000"), EventID: 9, CompetitorID: 1},
+		)
+		return result
+	}
+
+	type content struct {
+		name          string
+		config        config.Race
+		events        []events.Event
+		shots         int
+		maxShots      int
+		misses        int
+		penaltyMisses int
+	}
+
+	tests := []content{
+		{
+			name:          "default format",
+			config:        config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 150},
+			events:        visitEvents(1, 2, 3),
+			shots:         5,
+			maxShots:      5,
+			misses:        2,
+			penaltyMisses: 2,
+		},
+		{
+			name:          "spare rounds used",
+			config:        config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 150, SpareRounds: 3},
+			events:        visitEvents(1, 2, 3, 4),
+			shots:         5,
+			maxShots:      8,
+			misses:        1,
+			penaltyMisses: 1,
+		},
+		{
+			name:     "spare rounds not needed",
+			config:   config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 150, SpareRounds: 3},
+			events:   visitEvents(1, 2, 3, 4, 5),
+			shots:    5,
+			maxShots: 8,
+			misses:   0,
+		},
+		{
+			name:          "three targets",
+			config:        config.Race{Laps: 1, LapLen: 3000, PenaltyLen: 100, ShotsPerLine: 3, TargetsPerLine: 3},
+			events:        visitEvents(1, 3),
+			shots:         3,
+			maxShots:      3,
+			misses:        1,
+			penaltyMisses: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessCompetitor(tt.config, 1, tt.events)
+			if err != nil {
+				t.Fatalf("ProcessCompetitor() error = %v", err)
+			}
+			if len(result.Shooting) != 1 {
+				t.Fatalf("Shooting = %v, want a single visit", result.Shooting)
+			}
+			visit := result.Shooting[0]
+			if visit.Shots != tt.shots || visit.Misses != tt.misses || visit.Targets != tt.config.Targets() {
+				t.Errorf("visit shots/misses/targets = %d/%d/%d, want %d/%d/%d",
+					visit.Shots, visit.Misses, visit.Targets, tt.shots, tt.misses, tt.config.Targets())
+			}
+			if visit.MaxShots != tt.maxShots {
+				t.Errorf("visit max shots = %d, want %d", visit.MaxShots, tt.maxShots)
+			}
+			if result.Penalties[0].Misses != tt.penaltyMisses {
+				t.Errorf("penalty misses = %d, want %d", result.Penalties[0].Misses, tt.penaltyMisses)
+			}
+		})
+	}
+}
+
+func TestShootingStages(t *testing.T) {
+	raceConfig := config.Race{
+		Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2,
+		Stages: []config.Stage{
+			{Position: config.Prone, Lap: 1},
+			{Position: config.Standing, Lap: 2, Shots: 4},
+		},
+	}
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("10:00:00.000")},
+		{Time: clock.MustParse("10:00:01.000"), EventID: 4, CompetitorID: 1},
+		{Time: clock.MustParse("10:08:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("10:08:01.000"), EventID: 6, CompetitorID: 1, Target: 1},
+		{Time: clock.MustParse("10:08:02.000"), EventID: 6, CompetitorID: 1, Target: 2},
+		{Time: clock.MustParse("10:08:10.000"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:15:00.000"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("10:23:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 2},
+		{Time: clock.MustParse("10:23:01.000"), EventID: 6, CompetitorID: 1, Target: 3},
+		{Time: clock.MustParse("10:23:10.000"), EventID: 7, CompetitorID: 1},
+		{Time: clock.MustParse("10:30:00.000"), EventID: 5, CompetitorID: 1, FiringRange: 1},
+		{Time: clock.MustParse("10:30:10.000"), EventID: 7, CompetitorID: 1},
+	}
+
+	result, err := ProcessCompetitor(raceConfig, 1, competitorEvents)
+	if err != nil {
+		t.Fatalf("ProcessCompetitor() error = %v", err)
+	}
+
+	type stage struct {
+		position string
+		lap      int
+		shots    int
+	}
+	var got []stage
+	for _, visit := range result.Shooting {
+		got = append(got, stage{visit.Position, visit.Lap, visit.Shots})
+	}
+	want := []stage{{config.Prone, 1, 5}, {config.Standing, 2, 4}, {"", 2, 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stages = %v, want %v", got, want)
+	}
+
+	wantStats := []PositionStats{
+		{Position: config.Prone, Hits: 2, Shots: 5},
+		{Position: config.Standing, Hits: 1, Shots: 4},
+	}
+	if stats := result.ByPosition(); !reflect.DeepEqual(stats, wantStats) {
+		t.Errorf("ByPosition() = %v, want %v", stats, wantStats)
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"fmt"
+	"slices"
+)
+
+// startWindow is the slot drawn for a competitor: the start must happen in [open, close]
+type startWindow struct {
+	open  clock.Time
+	close clock.Time
+}
+
+// newStartWindow builds the window for a drawn start time. Without a valid
+// StartDelta in the config the start is not enforced.
+func newStartWindow(drawn clock.Time, startDelta string) (startWindow, bool) {
+	delta, err := clock.ParseDuration(startDelta)
+	if err != nil || drawn.IsZero() {
+		return startWindow{}, false
+	}
+	return startWindow{open: drawn, close: drawn.Add(delta)}, true
+}
+
+// check tells whether a start at startedAt is allowed. For a missed slot it returns
+// the time of disqualification and the reason.
+func (w startWindow) check(startedAt clock.Time) (disqualifiedAt clock.Time, reason string, ok bool) {
+	switch {
+	case startedAt.IsZero():
+		return w.close, "did not start", false
+	case startedAt.Before(w.open):
+		return startedAt, fmt.Sprintf("started at %s before the start window opened at %s", startedAt, w.open), false
+	case startedAt.After(w.close):
+		return w.close, fmt.Sprintf("started at %s after the start window closed at %s", startedAt, w.close), false
+	}
+	return clock.Time{}, "", true
+}
+
+// missed tells whether the window is already closed at clock time now without a start
+func (w startWindow) missed(now clock.Time) bool {
+	return now.After(w.close)
+}
+
+// scheduledStart is the start time the config's start mode plans for a competitor.
+// A draw with event 2 takes precedence, so drawn competitors are never scheduled.
+func scheduledStart(config config.Race, competitorID int, competitorEvents []events.Event) (clock.Time, bool) {
+	if drawn(competitorEvents) {
+		return clock.Time{}, false
+	}
+	return config.PlannedStart(competitorID)
+}
+
+// drawn tells whether the start time of the competitor is drawn with event 2
+func drawn(competitorEvents []events.Event) bool {
+	return slices.ContainsFunc(competitorEvents, func(event events.Event) bool {
+		return event.EventID == events.StartTimeDrawn
+	})
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"CompetitionLogger/internal/config"
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStartModes(t *testing.T) {
+	base := config.Race{Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:00:30"}
+	individual := base
+	individual.StartInterval = "00:01:00"
+	mass := base
+	mass.StartMode = config.StartMass
+	pursuit := base
+	pursuit.StartMode = config.StartPursuit
+	pursuit.Handicaps = map[int]time.Duration{2: 0, 3: 45 * time.Second}
+
+	race := func(competitorID int, startedAt string) []events.Event {
+		return []events.Event{
+			{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: competitorID},
+			{Time: clock.MustParse(startedAt), EventID: 4, CompetitorID: competitorID},
+			{Time: clock.MustParse("10:20:00.000"), EventID: 10, CompetitorID: competitorID},
+			{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: competitorID},
+		}
+	}
+
+	type content struct {
+		name         string
+		config       config.Race
+		competitorID int
+		events       []events.Event
+		status       string
+		totalTime    time.Duration
+		outgoing     []events.Event
+	}
+
+	tests := []content{
+		{
+			name:         "individual interval",
+			config:       individual,
+			competitorID: 3,
+			events:       race(3, "10:02:10.000"),
+			status:       "Finished",
+			totalTime:    18 * time.Minute,
+			outgoing:     []events.Event{{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 3}},
+		},
+		{
+			name:         "individual interval false start",
+			config:       individual,
+			competitorID: 3,
+			events:       race(3, "10:01:59.000"),
+			status:       "NotStarted",
+			outgoing:     []events.Event{{Time: clock.MustParse("10:01:59.000"), EventID: 32, CompetitorID: 3}},
+		},
+		{
+			name:         "mass start",
+			config:       mass,
+			competitorID: 7,
+			events:       race(7, "10:00:05.000"),
+			status:       "Finished",
+			totalTime:    20 * time.Minute,
+			outgoing:     []events.Event{{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 7}},
+		},
+		{
+			name:         "mass start late",
+			config:       mass,
+			competitorID: 7,
+			events:       race(7, "10:01:00.000"),
+			status:       "NotStarted",
+			outgoing:     []events.Event{{Time: clock.MustParse("10:00:30.000"), EventID: 32, CompetitorID: 7}},
+		},
+		{
+			name:         "pursuit handicap",
+			config:       pursuit,
+			competitorID: 3,
+			events:       race(3, "10:00:45.000"),
+			status:       "Finished",
+			totalTime:    19*time.Minute + 15*time.Second,
+			outgoing:     []events.Event{{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 3}},
+		},
+		{
+			name:         "pursuit before handicap",
+			config:       pursuit,
+			competitorID: 3,
+			events:       race(3, "10:00:00.000"),
+			status:       "NotStarted",
+			outgoing:     []events.Event{{Time: clock.MustParse("10:00:00.000"), EventID: 32, CompetitorID: 3}},
+		},
+		{
+			name:         "draw takes precedence",
+			config:       mass,
+			competitorID: 7,
+			events: []events.Event{
+				{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 7},
+				{Time: clock.MustParse("09:01:00.000"), EventID: 2, CompetitorID: 7, StartTime: clock.MustParse("10:05:00.000")},
+				{Time: clock.MustParse("10:05:10.000"), EventID: 4, CompetitorID: 7},
+				{Time: clock.MustParse("10:20:00.000"), EventID: 10, CompetitorID: 7},
+				{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 7},
+			},
+			status:    "Finished",
+			totalTime: 15 * time.Minute,
+			outgoing:  []events.Event{{Time: clock.MustParse("10:20:00.000"), EventID: 33, CompetitorID: 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessCompetitor(tt.config, tt.competitorID, tt.events)
+			if err != nil {
+				t.Fatalf("ProcessCompetitor() error = %v", err)
+			}
+			if result.Status != tt.status || result.TotalTime != tt.totalTime {
+				t.Errorf("ProcessCompetitor() = %s in %v, want %s in %v", result.Status, result.TotalTime, tt.status, tt.totalTime)
+			}
+			if tt.status == "Finished" && !result.Consistent() {
+				t.Errorf("Violations = %v, want none", result.Violations)
+			}
+			if got := Outgoing(tt.config, tt.competitorID, tt.events); !reflect.DeepEqual(got, tt.outgoing) {
+				t.Errorf("Outgoing() = %v, want %v", got, tt.outgoing)
+			}
+		})
+	}
+}
+
+func TestStartModeUnplanned(t *testing.T) {
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 4},
+	}
+	configs := map[string]config.Race{
+		"individual without interval": {Laps: 1, Start: "10:00:00.000", StartDelta: "00:00:30"},
+		"pursuit without handicap":    {Laps: 1, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartPursuit},
+	}
+
+	for name, raceConfig := range configs {
+		t.Run(name, func(t *testing.T) {
+			if got := Outgoing(raceConfig, 4, competitorEvents); len(got) != 0 {
+				t.Errorf("Outgoing() = %v, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestPursuitWithoutHandicap(t *testing.T) {
+	raceConfig := config.Race{Laps: 1, LapLen: 3000, Start: "10:00:00.000", StartDelta: "00:00:30", StartMode: config.StartPursuit,
+		Handicaps: map[int]time.Duration{2: 0}}
+	competitorEvents := []events.Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 1, CompetitorID: 4},
+		{Time: clock.MustParse("10:00:10.000"), EventID: 4, CompetitorID: 4},
+	}
+
+	report, err := ProcessCompetitor(raceConfig, 4, competitorEvents)
+	if !errors.Is(err, config.ErrNoHandicap) {
+		t.Errorf("ProcessCompetitor() error = %v, want %v", err, config.ErrNoHandicap)
+	}
+	if report.Status != "Started" || report.Reason != "no handicap" || len(report.Laps) != raceConfig.Laps {
+		t.Errorf("ProcessCompetitor() = %+v, want a Started row with all laps and reason %q", report, "no handicap")
+	}
+}
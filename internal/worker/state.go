@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"CompetitionLogger/pkg/events"
+	"fmt"
+	"slices"
+)
+
+// State is the stage of the race a competitor is in
+type State int
+
+const (
+	Unregistered State = iota
+	Registered
+	Drawn
+	OnStartLine
+	Racing
+	OnRange
+	InPenalty
+	Finished
+	NotFinished
+	Disqualified
+)
+
+var stateNames = map[State]string{
+	Unregistered: "Unregistered",
+	Registered:   "Registered",
+	Drawn:        "Drawn",
+	OnStartLine:  "OnStartLine",
+	Racing:       "Racing",
+	OnRange:      "OnRange",
+	InPenalty:    "InPenalty",
+	Finished:     "Finished",
+	NotFinished:  "NotFinished",
+	Disqualified: "Disqualified",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// terminal states end the race of a competitor
+func (s State) terminal() bool {
+	return s == Finished || s == NotFinished || s == Disqualified
+}
+
+// transition is the set of states an event is allowed in and the state it leads to
+type transition struct {
+	from []State
+	to   State
+}
+
+var running = []State{Registered, Drawn, OnStartLine, Racing, OnRange, InPenalty}
+
+var transitions = map[int]transition{
+	events.Registered:      {from: []State{Unregistered}, to: Registered},
+	events.StartTimeDrawn:  {from: []State{Registered}, to: Drawn},
+	events.OnStartLine:     {from: []State{Drawn}, to: OnStartLine},
+	events.Started:         {from: []State{Drawn, OnStartLine}, to: Racing},
+	events.OnFiringRange:   {from: []State{Racing}, to: OnRange},
+	events.TargetHit:       {from: []State{OnRange}, to: OnRange},
+	events.LeftFiringRange: {from: []State{OnRange}, to: Racing},
+	events.EnteredPenalty:  {from: []State{Racing}, to: InPenalty},
+	events.LeftPenalty:     {from: []State{InPenalty}, to: Racing},
+	events.EndedMainLap:    {from: []State{Racing}, to: Racing},
+	events.CannotContinue:  {from: running, to: NotFinished},
+	events.Disqualified:    {from: running, to: Disqualified},
+	events.Finished:        {from: []State{Racing}, to: Finished},
+	events.HandedOver:      {from: []State{Racing, Finished}, to: Finished},
+}
+
+// Violation is an event that is impossible in the state the competitor was in
+type Violation struct {
+	Event events.Event
+	State State
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("[%s] event %d is not allowed in state %s", v.Event.Time, v.Event.EventID, v.State)
+}
+
+// stateMachine follows a competitor through the race and records impossible events.
+// A scheduled competitor gets the start time from the start mode, so registration
+// already leaves them drawn. A relay leg after the first may wait on the start line
+// before the handover draws it.
+type stateMachine struct {
+	state      State
+	violations []Violation
+	scheduled  bool
+	leg        bool
+}
+
+// unschedule drops the start time planned by the start mode once the competitor
+// is drawn after all, so the draw is expected like for anyone registered
+func (m *stateMachine) unschedule() {
+	if m.scheduled && m.state == Drawn {
+		m.state = Registered
+	}
+	m.scheduled = false
+}
+
+// apply moves the machine by event. An impossible event is recorded as a violation
+// and the machine still moves to its target state to resync with the data.
+// Once disqualified the competitor is no longer checked.
+func (m *stateMachine) apply(event events.Event) {
+	if m.state == Disqualified {
+		return
+	}
+	if m.leg {
+		switch {
+		case event.EventID == events.OnStartLine && m.state == Registered:
+			m.state = OnStartLine
+			return
+		case event.EventID == events.StartTimeDrawn && m.state == OnStartLine:
+			return
+		}
+	}
+
+	t, ok := transitions[event.EventID]
+	if !ok {
+		m.violations = append(m.violations, Violation{Event: event, State: m.state})
+		return
+	}
+	if !slices.Contains(t.from, m.state) {
+		m.violations = append(m.violations, Violation{Event: event, State: m.state})
+		if m.state.terminal() {
+			return
+		}
+	}
+	m.state = t.to
+	if m.scheduled && m.state == Registered {
+		m.state = Drawn
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"CompetitionLogger/pkg/clock"
+	"CompetitionLogger/pkg/events"
+	"reflect"
+	"testing"
+)
+
+func TestStateMachine(t *testing.T) {
+	type content struct {
+		name       string
+		events     []int
+		state      State
+		violations []int
+	}
+
+	tests := []content{
+		{
+			name:   "full race",
+			events: []int{1, 2, 3, 4, 5, 6, 6, 7, 8, 9, 10, 5, 7, 10, 33},
+			state:  Finished,
+		},
+		{
+			name:   "start without start line",
+			events: []int{1, 2, 4, 10},
+			state:  Racing,
+		},
+		{
+			name:       "hit without firing range",
+			events:     []int{1, 2, 3, 4, 6, 6, 7},
+			state:      Racing,
+			violations: []int{6},
+		},
+		{
+			name:       "left penalty never entered",
+			events:     []int{1, 2, 3, 4, 9},
+			state:      Racing,
+			violations: []int{9},
+		},
+		{
+			name:       "lap before start",
+			events:     []int{1, 2, 10, 4},
+			state:      Racing,
+			violations: []int{10, 4},
+		},
+		{
+			name:       "events after finish",
+			events:     []int{1, 2, 4, 10, 33, 5},
+			state:      Finished,
+			violations: []int{5},
+		},
+		{
+			name:   "late start after disqualification",
+			events: []int{1, 2, 32, 4, 10},
+			state:  Disqualified,
+		},
+		{
+			name:   "can't continue on the firing range",
+			events: []int{1, 2, 3, 4, 5, 11},
+			state:  NotFinished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var machine stateMachine
+			for _, eventID := range tt.events {
+				machine.apply(events.Event{Time: clock.MustParse("10:00:00.000"), EventID: eventID, CompetitorID: 1})
+			}
+
+			if machine.state != tt.state {
+				t.Errorf("state = %s, want %s", machine.state, tt.state)
+			}
+			var violations []int
+			for _, violation := range machine.violations {
+				violations = append(violations, violation.Event.EventID)
+			}
+			if !reflect.DeepEqual(violations, tt.violations) {
+				t.Errorf("violations = %v, want %v", violations, tt.violations)
+			}
+		})
+	}
+}
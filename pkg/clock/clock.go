@@ -0,0 +1,165 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+var ErrInvalid = errors.New("invalid time")
+
+// Time is a time of day on the race clock with millisecond precision.
+// Times after midnight of the race day keep counting past 24 hours, so a race
+// running over midnight stays ordered. The zero Time is unset.
+type Time struct {
+	sinceMidnight time.Duration
+	valid         bool
+}
+
+// Parse reads a clock time "HH:MM:SS.sss", milliseconds are optional
+func Parse(s string) (Time, error) {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return Time{}, err
+	}
+	if d >= day {
+		return Time{}, fmt.Errorf("%w %q: hours out of range", ErrInvalid, s)
+	}
+	return Time{sinceMidnight: d, valid: true}, nil
+}
+
+// MustParse is like Parse but panics on error, for constants and tests
+func MustParse(s string) Time {
+	t, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ParseDuration reads a duration "HH:MM:SS.sss", milliseconds are optional
+func ParseDuration(s string) (time.Duration, error) {
+	hms, millis, hasMillis := strings.Cut(s, ".")
+	parts := strings.Split(hms, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("%w %q: want HH:MM:SS.sss", ErrInvalid, s)
+	}
+
+	hours, ok := digits(parts[0], 2)
+	if !ok {
+		return 0, fmt.Errorf("%w %q: bad hours", ErrInvalid, s)
+	}
+	minutes, ok := digits(parts[1], 2)
+	if !ok || minutes > 59 {
+		return 0, fmt.Errorf("%w %q: bad minutes", ErrInvalid, s)
+	}
+	seconds, ok := digits(parts[2], 2)
+	if !ok || seconds > 59 {
+		return 0, fmt.Errorf("%w %q: bad seconds", ErrInvalid, s)
+	}
+	milliseconds := 0
+	if hasMillis {
+		milliseconds, ok = digits(millis, 3)
+		if !ok {
+			return 0, fmt.Errorf("%w %q: bad milliseconds", ErrInvalid, s)
+		}
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(milliseconds)*time.Millisecond, nil
+}
+
+// MustParseDuration is like ParseDuration but panics on error, for constants and tests
+func MustParseDuration(s string) time.Duration {
+	d, err := ParseDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// FormatDuration prints d as "HH:MM:SS.sss" with trailing zeros
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	hours := int(d / time.Hour)
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+	milliseconds := int(d/time.Millisecond) % 1000
+	return fmt.Sprintf("%s%02d:%02d:%02d.%03d", sign, hours, minutes, seconds, milliseconds)
+}
+
+// String prints the time of day as "HH:MM:SS.sss", an unset Time prints empty
+func (t Time) String() string {
+	if !t.valid {
+		return ""
+	}
+	return FormatDuration(t.sinceMidnight % day)
+}
+
+func (t Time) IsZero() bool {
+	return !t.valid
+}
+
+func (t Time) Add(d time.Duration) Time {
+	return Time{sinceMidnight: t.sinceMidnight + d, valid: t.valid}
+}
+
+// Sub returns t-u, it is negative when t is before u
+func (t Time) Sub(u Time) time.Duration {
+	return t.sinceMidnight - u.sinceMidnight
+}
+
+func (t Time) Before(u Time) bool {
+	return t.sinceMidnight < u.sinceMidnight
+}
+
+func (t Time) After(u Time) bool {
+	return t.sinceMidnight > u.sinceMidnight
+}
+
+// Compare returns -1, 0 or +1 for t before, equal to or after u
+func (t Time) Compare(u Time) int {
+	switch {
+	case t.Before(u):
+		return -1
+	case t.After(u):
+		return 1
+	}
+	return 0
+}
+
+// Rollover moves t to the following day when it is more than twelve hours
+// before prev: events are sequential, so the clock passed midnight in between.
+func (t Time) Rollover(prev Time) Time {
+	if !t.valid || !prev.valid {
+		return t
+	}
+	for prev.sinceMidnight-t.sinceMidnight > day/2 {
+		t.sinceMidnight += day
+	}
+	return t
+}
+
+func digits(s string, width int) (int, bool) {
+	if len(s) != width {
+		return 0, false
+	}
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n, true
+}
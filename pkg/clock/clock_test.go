@@ -0,0 +1,240 @@
+package clock
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	type content struct {
+		name     string
+		timeStr  string
+		expected string
+		wantErr  bool
+	}
+	tests := []content{
+		{
+			name:     "normal time",
+			timeStr:  "09:59:03.872",
+			expected: "09:59:03.872",
+		},
+		{
+			name:     "missing milliseconds",
+			timeStr:  "09:30:00",
+			expected: "09:30:00.000",
+		},
+		{
+			name:    "invalid format",
+			timeStr: "invalid",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			timeStr: "",
+			wantErr: true,
+		},
+		{
+			name:    "hours out of range",
+			timeStr: "24:00:00.000",
+			wantErr: true,
+		},
+		{
+			name:    "minutes out of range",
+			timeStr: "09:60:00.000",
+			wantErr: true,
+		},
+		{
+			name:    "short milliseconds",
+			timeStr: "09:30:00.5",
+			wantErr: true,
+		},
+		{
+			name:    "signed field",
+			timeStr: "+9:30:00.000",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.timeStr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.timeStr, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("Parse(%q) error = %v, want ErrInvalid", tt.timeStr, err)
+				}
+				return
+			}
+			if result.String() != tt.expected {
+				t.Errorf("Parse(%q) = %q, want %q", tt.timeStr, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	type content struct {
+		name     string
+		timeStr  string
+		expected time.Duration
+		wantErr  bool
+	}
+	tests := []content{
+		{
+			name:     "normal duration",
+			timeStr:  "00:29:03.872",
+			expected: 1743872 * time.Millisecond,
+		},
+		{
+			name:     "zero duration",
+			timeStr:  "00:00:00.000",
+			expected: 0,
+		},
+		{
+			name:     "only milliseconds",
+			timeStr:  "00:00:00.500",
+			expected: 500 * time.Millisecond,
+		},
+		{
+			name:     "missing milliseconds",
+			timeStr:  "00:01:30",
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "more than a day",
+			timeStr:  "25:00:00.000",
+			expected: 25 * time.Hour,
+		},
+		{
+			name:    "invalid format",
+			timeStr: "invalid",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDuration(tt.timeStr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.timeStr, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.timeStr, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	type content struct {
+		name     string
+		duration time.Duration
+		expected string
+	}
+
+	tests := []content{
+		{
+			name:     "normal duration",
+			duration: 1743872 * time.Millisecond,
+			expected: "00:29:03.872",
+		},
+		{
+			name:     "zero duration",
+			duration: 0,
+			expected: "00:00:00.000",
+		},
+		{
+			name:     "negative duration",
+			duration: -1 * time.Second,
+			expected: "-00:00:01.000",
+		},
+		{
+			name:     "only milliseconds",
+			duration: 500 * time.Millisecond,
+			expected: "00:00:00.500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatDuration(tt.duration)
+			if result != tt.expected {
+				t.Errorf("FormatDuration(%v) = %q, want %q", tt.duration, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSub(t *testing.T) {
+	type content struct {
+		name     string
+		t1       string
+		t2       string
+		expected time.Duration
+	}
+	tests := []content{
+		{
+			name:     "normal subtraction",
+			t1:       "09:30:00.000",
+			t2:       "09:59:03.872",
+			expected: 1743872 * time.Millisecond,
+		},
+		{
+			name:     "negative duration",
+			t1:       "09:59:03.872",
+			t2:       "09:30:00.000",
+			expected: -1743872 * time.Millisecond,
+		},
+		{
+			name:     "same time",
+			t1:       "09:30:00.000",
+			t2:       "09:30:00.000",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MustParse(tt.t2).Sub(MustParse(tt.t1))
+			if result != tt.expected {
+				t.Errorf("%s - %s = %v, want %v", tt.t2, tt.t1, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRollover(t *testing.T) {
+	prev := MustParse("23:59:58.000")
+
+	next := MustParse("00:00:02.000").Rollover(prev)
+	if !next.After(prev) {
+		t.Errorf("Rollover() = %v, want after %v", next, prev)
+	}
+	if next.String() != "00:00:02.000" {
+		t.Errorf("Rollover().String() = %q, want %q", next, "00:00:02.000")
+	}
+	if got := next.Sub(prev); got != 4*time.Second {
+		t.Errorf("Rollover().Sub(prev) = %v, want %v", got, 4*time.Second)
+	}
+
+	same := MustParse("23:59:57.000").Rollover(prev)
+	if !same.Before(prev) {
+		t.Errorf("Rollover() moved %v past midnight", same)
+	}
+}
+
+func TestZero(t *testing.T) {
+	var zero Time
+	if !zero.IsZero() {
+		t.Errorf("IsZero() = false for the zero Time")
+	}
+	if zero.String() != "" {
+		t.Errorf("String() = %q for the zero Time, want empty", zero.String())
+	}
+	if MustParse("00:00:00.000").IsZero() {
+		t.Errorf("IsZero() = true for midnight")
+	}
+}
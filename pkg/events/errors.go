@@ -0,0 +1,40 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons of a ParseError, match them with errors.Is
+var (
+	ErrBadTime             = errors.New("bad time")
+	ErrMissingFields       = errors.New("missing fields")
+	ErrBadEventID          = errors.New("bad event id")
+	ErrUnknownEvent        = errors.New("unknown event id")
+	ErrBadCompetitorID     = errors.New("bad competitor id")
+	ErrMissingParameter    = errors.New("missing extra param")
+	ErrUnexpectedParameter = errors.New("unexpected extra param")
+	ErrBadParameter        = errors.New("bad extra param")
+	// ErrRead ends the events: a line could not be read at all, like one longer
+	// than bufio.MaxScanTokenSize
+	ErrRead = errors.New("cannot read events")
+)
+
+// ParseError points at a line of the events file that could not be parsed
+type ParseError struct {
+	Line   int
+	Column int
+	Raw    string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Column == 0 && e.Raw == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v: %q", e.Line, e.Column, e.Err, e.Raw)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,34 @@
+package events
+
+// names of the events for machine-readable output, matching the constants
+var names = map[int]string{
+	Registered:      "Registered",
+	StartTimeDrawn:  "StartTimeDrawn",
+	OnStartLine:     "OnStartLine",
+	Started:         "Started",
+	OnFiringRange:   "OnFiringRange",
+	TargetHit:       "TargetHit",
+	LeftFiringRange: "LeftFiringRange",
+	EnteredPenalty:  "EnteredPenalty",
+	LeftPenalty:     "LeftPenalty",
+	EndedMainLap:    "EndedMainLap",
+	CannotContinue:  "CannotContinue",
+	HandedOver:      "HandedOver",
+	Disqualified:    "Disqualified",
+	Finished:        "Finished",
+}
+
+// Name of the event ID, "Unknown" for an ID that is neither incoming nor outgoing
+func Name(eventID int) string {
+	if name, ok := names[eventID]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Incoming tells whether the event ID comes from the input, the others are
+// generated by the system
+func Incoming(eventID int) bool {
+	_, ok := schema[eventID]
+	return ok
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"CompetitionLogger/pkg/clock"
+)
+
+// DefaultTargets is the number of targets on a firing line unless Options say otherwise
+const DefaultTargets = 5
+
+type paramKind int
+
+const (
+	noParam paramKind = iota
+	startTimeParam
+	firingRangeParam
+	targetParam
+	commentParam
+)
+
+// schema is the extra param taken by each incoming event
+var schema = map[int]paramKind{
+	Registered:      noParam,
+	StartTimeDrawn:  startTimeParam,
+	OnStartLine:     noParam,
+	Started:         noParam,
+	OnFiringRange:   firingRangeParam,
+	TargetHit:       targetParam,
+	LeftFiringRange: noParam,
+	EnteredPenalty:  noParam,
+	LeftPenalty:     noParam,
+	EndedMainLap:    noParam,
+	CannotContinue:  commentParam,
+	HandedOver:      noParam,
+}
+
+// parseParams checks the extra params of a line against the schema and fills the typed fields of event
+func parseParams(event *Event, line string, params []field, opts Options) *ParseError {
+	fail := func(column int, err error) *ParseError {
+		return &ParseError{Column: column, Raw: line, Err: err}
+	}
+
+	kind := schema[event.EventID]
+	switch {
+	case kind == noParam && len(params) > 0:
+		return fail(params[0].column, fmt.Errorf("%w for event %d", ErrUnexpectedParameter, event.EventID))
+	case kind == noParam:
+		return nil
+	case len(params) == 0:
+		return fail(len(strings.TrimRightFunc(line, unicode.IsSpace))+1, fmt.Errorf("%w for event %d", ErrMissingParameter, event.EventID))
+	case kind != commentParam && len(params) > 1:
+		return fail(params[1].column, fmt.Errorf("%w for event %d", ErrUnexpectedParameter, event.EventID))
+	}
+
+	param := params[0]
+	switch kind {
+	case startTimeParam:
+		startTime, err := clock.Parse(param.text)
+		if err != nil {
+			return fail(param.column, fmt.Errorf("%w: start time: %w", ErrBadParameter, err))
+		}
+		event.StartTime = startTime
+	case firingRangeParam:
+		firingRange, err := parseNumber(param.text, opts.FiringLines)
+		if err != nil {
+			return fail(param.column, fmt.Errorf("%w: firing range: %w", ErrBadParameter, err))
+		}
+		event.FiringRange = firingRange
+	case targetParam:
+		targets := opts.Targets
+		if targets <= 0 {
+			targets = DefaultTargets
+		}
+		target, err := parseNumber(param.text, targets)
+		if err != nil {
+			return fail(param.column, fmt.Errorf("%w: target: %w", ErrBadParameter, err))
+		}
+		event.Target = target
+	case commentParam:
+		texts := make([]string, 0, len(params))
+		for _, p := range params {
+			texts = append(texts, p.text)
+		}
+		event.Comment = strings.Join(texts, " ")
+	}
+	return nil
+}
+
+// parseNumber reads a number in 1..upper, an upper bound of zero is not checked
+func parseNumber(s string, upper int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < 1 || (upper > 0 && n > upper) {
+		if upper > 0 {
+			return 0, fmt.Errorf("%d is out of range 1..%d", n, upper)
+		}
+		return 0, fmt.Errorf("%d is not positive", n)
+	}
+	return n, nil
+}
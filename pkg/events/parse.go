@@ -3,23 +3,53 @@ package events
 import (
 	"bufio"
 	"context"
+	"fmt"
+	"io"
+	"iter"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
+	"unicode"
 
+	"CompetitionLogger/pkg/clock"
 	"CompetitionLogger/pkg/logger"
 	"go.uber.org/zap"
 )
 
 type Event struct {
-	Time         string
+	Time         clock.Time
 	EventID      int
 	CompetitorID int
-	ExtraParams  string
+	StartTime    clock.Time
+	FiringRange  int
+	Target       int
+	Comment      string
 }
 
+// Incoming events
+const (
+	Registered      = 1
+	StartTimeDrawn  = 2
+	OnStartLine     = 3
+	Started         = 4
+	OnFiringRange   = 5
+	TargetHit       = 6
+	LeftFiringRange = 7
+	EnteredPenalty  = 8
+	LeftPenalty     = 9
+	EndedMainLap    = 10
+	CannotContinue  = 11
+	HandedOver      = 12
+)
+
+// Outgoing events
+const (
+	Disqualified = 32
+	Finished     = 33
+)
+
 type EventStore struct {
 	events []Event
 }
@@ -35,96 +65,190 @@ func LoadEvents(ctx context.Context, pathToEvents string) *os.File {
 	return eventsFile
 }
 
-func ParseEvents(ctx context.Context, eventsFile *os.File) *EventStore {
+// Options tune ParseEvents
+type Options struct {
+	// Strict stops parsing at the first malformed line
+	Strict bool
+	// FiringLines bounds the firing range of event 5, zero leaves it unchecked
+	FiringLines int
+	// Targets bounds the target of event 6, zero means DefaultTargets
+	Targets int
+}
+
+// ParseEvents reads the events file line by line. Malformed lines are skipped and
+// reported as ParseErrors; in strict mode the first one aborts parsing and the
+// store holds the events read before it. A line that cannot be read aborts
+// parsing in any mode with a last ParseError wrapping ErrRead.
+func ParseEvents(ctx context.Context, eventsFile *os.File, opts Options) (*EventStore, []*ParseError) {
 	store := &EventStore{}
 	if eventsFile == nil {
 		logger.GetFromContext(ctx).Warn("Events file is nil")
-		return store
+		return store, nil
 	}
 
-	scanner := bufio.NewScanner(eventsFile)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		event := parseEvent(ctx, line)
-		if event.Time == "" && event.EventID == 0 && event.CompetitorID == 0 {
+	var parseErrors []*ParseError
+	for event, parseErr := range Stream(ctx, eventsFile, opts) {
+		if parseErr != nil {
+			parseErrors = append(parseErrors, parseErr)
+			if opts.Strict {
+				return store, parseErrors
+			}
 			continue
 		}
 		store.events = append(store.events, event)
 	}
 
-	if err := scanner.Err(); err != nil {
-		logger.GetFromContext(ctx).Error("error scanning file", zap.Error(err))
-		return store
+	slices.SortStableFunc(store.events, func(a, b Event) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	logger.GetFromContext(ctx).Info("success parsed events", zap.Int("errors", len(parseErrors)))
+	return store, parseErrors
+}
+
+// Stream parses the events of r as the lines arrive, yielding either an event or
+// the ParseError of a malformed line. Times roll over midnight like in
+// ParseEvents, but the events keep the input order. In strict mode the stream
+// ends after the first ParseError, and always after one wrapping ErrRead.
+func Stream(ctx context.Context, r io.Reader, opts Options) iter.Seq2[Event, *ParseError] {
+	return func(yield func(Event, *ParseError) bool) {
+		var prev clock.Time
+		lineNumber := 0
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lineNumber++
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			event, parseErr := parseEvent(ctx, line, opts)
+			if parseErr != nil {
+				parseErr.Line = lineNumber
+				logger.GetFromContext(ctx).Error("error parsing event", zap.Error(parseErr))
+				if !yield(Event{}, parseErr) || opts.Strict {
+					return
+				}
+				continue
+			}
+			event.Time = event.Time.Rollover(prev)
+			event.StartTime = event.StartTime.Rollover(event.Time)
+			prev = event.Time
+			if !yield(event, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			parseErr := &ParseError{Line: lineNumber + 1, Err: fmt.Errorf("%w: %w", ErrRead, err)}
+			logger.GetFromContext(ctx).Error("error scanning file", zap.Error(parseErr))
+			yield(Event{}, parseErr)
+		}
 	}
+}
 
-	logger.GetFromContext(ctx).Info("success parsed events")
-	return store
+// field is a whitespace separated token of a line with its 1-based column
+type field struct {
+	text   string
+	column int
 }
 
-func parseEvent(ctx context.Context, line string) Event {
-	if !strings.HasPrefix(line, "[") {
-		logger.GetFromContext(ctx).Error("incorrect time's format", zap.String("line", line))
-		return Event{}
+func splitFields(line string, offset int) []field {
+	var result []field
+	start := -1
+	for i, r := range line[offset:] {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				result = append(result, field{text: line[offset+start : offset+i], column: offset + start + 1})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		result = append(result, field{text: line[offset+start:], column: offset + start + 1})
+	}
+	return result
+}
+
+func parseEvent(ctx context.Context, line string, opts Options) (Event, *ParseError) {
+	fail := func(column int, err error) (Event, *ParseError) {
+		return Event{}, &ParseError{Column: column, Raw: line, Err: err}
+	}
+
+	openIdx := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+	if !strings.HasPrefix(line[openIdx:], "[") {
+		return fail(openIdx+1, fmt.Errorf("%w: missing open bracket", ErrBadTime))
 	}
 
 	endTimeIdx := strings.Index(line, "]")
 	if endTimeIdx == -1 {
-		logger.GetFromContext(ctx).Error("incorrect time's format: missing close bracket")
-		return Event{}
+		return fail(openIdx+1, fmt.Errorf("%w: missing close bracket", ErrBadTime))
 	}
 
-	timeStr := line[1:endTimeIdx]
-	layout := "15:04:05.000"
-	_, err := time.Parse(layout, timeStr)
+	timeStr := line[openIdx+1 : endTimeIdx]
+	eventTime, err := clock.Parse(timeStr)
 	if err != nil {
-		logger.GetFromContext(ctx).Error("error parsing time", zap.String("time", timeStr), zap.Error(err))
-		return Event{}
+		return fail(openIdx+2, fmt.Errorf("%w: %w", ErrBadTime, err))
 	}
 
-	formattedTime := timeStr
-
-	rest := strings.TrimSpace(line[endTimeIdx+1:])
-	parts := strings.Fields(rest)
+	parts := splitFields(line, endTimeIdx+1)
 	if len(parts) < 2 {
-		logger.GetFromContext(ctx).Error("incorrect format: missing fields", zap.String("line", line))
-		return Event{}
+		return fail(len(strings.TrimRightFunc(line, unicode.IsSpace))+1, ErrMissingFields)
 	}
 
-	eventID, err := strconv.Atoi(parts[0])
+	eventID, err := strconv.Atoi(parts[0].text)
 	if err != nil {
-		logger.GetFromContext(ctx).Error("error parsing event id", zap.String("eventID", parts[0]), zap.Error(err))
-		return Event{}
+		return fail(parts[0].column, fmt.Errorf("%w %q", ErrBadEventID, parts[0].text))
+	}
+	if _, ok := schema[eventID]; !ok {
+		return fail(parts[0].column, fmt.Errorf("%w %d", ErrUnknownEvent, eventID))
 	}
 
-	competitorID, err := strconv.Atoi(parts[1])
+	competitorID, err := strconv.Atoi(parts[1].text)
 	if err != nil {
-		logger.GetFromContext(ctx).Error("error parsing competitor id", zap.String("competitorID", parts[1]), zap.Error(err))
-		return Event{}
+		return fail(parts[1].column, fmt.Errorf("%w %q", ErrBadCompetitorID, parts[1].text))
 	}
 
-	extraParams := ""
-	if len(parts) > 2 {
-		extraParams = strings.Join(parts[2:], " ")
+	event := Event{
+		Time:         eventTime,
+		EventID:      eventID,
+		CompetitorID: competitorID,
+	}
+	if parseErr := parseParams(&event, line, parts[2:], opts); parseErr != nil {
+		return Event{}, parseErr
 	}
 
 	logger.GetFromContext(ctx).Info("success parse line into Event")
-	return Event{
-		Time:         formattedTime,
-		EventID:      eventID,
-		CompetitorID: competitorID,
-		ExtraParams:  extraParams,
+	return event, nil
+}
+
+// All iterates over the events in chronological order, events sharing a time keep the input order
+func (s *EventStore) All() iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		for _, event := range s.events {
+			if !yield(event) {
+				return
+			}
+		}
 	}
 }
 
+// Len returns the number of stored events
+func (s *EventStore) Len() int {
+	return len(s.events)
+}
+
 // ByTime using time as a key
+//
+// Deprecated: events sharing a time overwrite each other, use All.
 func (s *EventStore) ByTime() map[string]Event {
 	result := make(map[string]Event)
 	for _, event := range s.events {
-		result[event.Time] = event
+		result[event.Time.String()] = event
 	}
 	return result
 }
@@ -138,6 +262,15 @@ func (s *EventStore) ByCompetitor() map[int][]Event {
 	return result
 }
 
+// Insert puts event into the timeline after every stored event with the same or earlier time
+func (s *EventStore) Insert(event Event) {
+	idx := sort.Search(len(s.events), func(i int) bool {
+		return s.events[i].Time.After(event.Time)
+	})
+	s.events = slices.Insert(s.events, idx, event)
+}
+
+// Deprecated: use All, which is already ordered.
 func SortMapByKey(eventsMap map[string]Event) []string {
 	keys := make([]string, 0, len(eventsMap))
 	for key := range eventsMap {
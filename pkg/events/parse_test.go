@@ -1,18 +1,21 @@
 package events
 
 import (
+	"CompetitionLogger/pkg/clock"
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"go.uber.org/zap"
+	"io"
+	"iter"
 	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
 	"testing"
-)
-
-const (
-	key = "logger"
+	"testing/iotest"
+	"time"
 )
 
 func TestLoadEvents(t *testing.T) {
@@ -20,7 +23,7 @@ func TestLoadEvents(t *testing.T) {
 		ctx context.Context
 	}
 
-	ctx := context.WithValue(context.Background(), key, zap.NewNop())
+	ctx := context.Background()
 
 	tests := []struct {
 		name        string
@@ -73,12 +76,15 @@ func TestLoadEvents(t *testing.T) {
 	}
 }
 
+// withoutParams are the incoming events that need no extra params
+var withoutParams = []int{1, 3, 4, 7, 8, 9, 10, 12}
+
 func TestParseEvents(t *testing.T) {
 	type args struct {
 		ctx context.Context
 	}
 
-	ctx := context.WithValue(context.Background(), key, zap.NewNop())
+	ctx := context.Background()
 
 	tests := []struct {
 		name       string
@@ -86,6 +92,7 @@ func TestParseEvents(t *testing.T) {
 		wantEvents []Event
 		wantByTime map[string]Event
 		wantByComp map[int][]Event
+		wantErrs   []int
 		wantNil    bool
 	}{
 		{
@@ -93,17 +100,17 @@ func TestParseEvents(t *testing.T) {
 			input: `[09:05:59.867] 1 1
 [09:15:00.841] 2 1 09:30:00.000`,
 			wantEvents: []Event{
-				{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:05:59.867": {Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
 			wantNil: false,
@@ -123,25 +130,25 @@ func TestParseEvents(t *testing.T) {
 					[09:16:00.000] 1 2
 					[09:17:00.000] 4 2`,
 			wantEvents: []Event{
-				{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
-				{Time: "09:16:00.000", EventID: 1, CompetitorID: 2, ExtraParams: ""},
-				{Time: "09:17:00.000", EventID: 4, CompetitorID: 2, ExtraParams: ""},
+				{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				{Time: clock.MustParse("09:16:00.000"), EventID: 1, CompetitorID: 2},
+				{Time: clock.MustParse("09:17:00.000"), EventID: 4, CompetitorID: 2},
 			},
 			wantByTime: map[string]Event{
-				"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
-				"09:16:00.000": {Time: "09:16:00.000", EventID: 1, CompetitorID: 2, ExtraParams: ""},
-				"09:17:00.000": {Time: "09:17:00.000", EventID: 4, CompetitorID: 2, ExtraParams: ""},
+				"09:05:59.867": {Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+				"09:16:00.000": {Time: clock.MustParse("09:16:00.000"), EventID: 1, CompetitorID: 2},
+				"09:17:00.000": {Time: clock.MustParse("09:17:00.000"), EventID: 4, CompetitorID: 2},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 				2: {
-					{Time: "09:16:00.000", EventID: 1, CompetitorID: 2, ExtraParams: ""},
-					{Time: "09:17:00.000", EventID: 4, CompetitorID: 2, ExtraParams: ""},
+					{Time: clock.MustParse("09:16:00.000"), EventID: 1, CompetitorID: 2},
+					{Time: clock.MustParse("09:17:00.000"), EventID: 4, CompetitorID: 2},
 				},
 			},
 			wantNil: false,
@@ -151,34 +158,36 @@ func TestParseEvents(t *testing.T) {
 			input: `[invalid] 1 1
 [09:15:00.841] 2 1 09:30:00.000`,
 			wantEvents: []Event{
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
-			wantNil: false,
+			wantErrs: []int{1},
+			wantNil:  false,
 		},
 		{
 			name: "invalid event ID",
 			input: `[09:05:59.867] invalid 1
 [09:15:00.841] 2 1 09:30:00.000`,
 			wantEvents: []Event{
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
-			wantNil: false,
+			wantErrs: []int{1},
+			wantNil:  false,
 		},
 		{
 			name:       "non-existent file",
@@ -196,17 +205,17 @@ func TestParseEvents(t *testing.T) {
 [09:15:00.841] 2 1 09:30:00.000
 `,
 			wantEvents: []Event{
-				{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:05:59.867": {Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
 			wantNil: false,
@@ -216,61 +225,62 @@ func TestParseEvents(t *testing.T) {
 			input: `[09:05:59.867] 1 invalid
 [09:15:00.841] 2 1 09:30:00.000`,
 			wantEvents: []Event{
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
-			wantNil: false,
+			wantErrs: []int{1},
+			wantNil:  false,
 		},
 		{
 			name: "missing fields",
 			input: `[09:05:59.867] 1
 [09:15:00.841] 2 1 09:30:00.000`,
 			wantEvents: []Event{
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByTime: map[string]Event{
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+				"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+					{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 				},
 			},
-			wantNil: false,
+			wantErrs: []int{1},
+			wantNil:  false,
 		},
 		{
 			name: "multiple extra params",
 			input: `[09:05:59.867] 1 1 some extra params
-[09:15:00.841] 2 1 09:30:00.000 another param`,
+[09:15:00.841] 2 1 09:30:00.000 another param
+[09:59:05.321] 11 1 Lost in the forest`,
 			wantEvents: []Event{
-				{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: "some extra params"},
-				{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000 another param"},
+				{Time: clock.MustParse("09:59:05.321"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
 			},
 			wantByTime: map[string]Event{
-				"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: "some extra params"},
-				"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000 another param"},
+				"09:59:05.321": {Time: clock.MustParse("09:59:05.321"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
 			},
 			wantByComp: map[int][]Event{
 				1: {
-					{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: "some extra params"},
-					{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000 another param"},
+					{Time: clock.MustParse("09:59:05.321"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
 				},
 			},
-			wantNil: false,
+			wantErrs: []int{1, 2},
+			wantNil:  false,
 		},
 		{
 			name: "many events",
 			input: func() string {
 				var builder strings.Builder
 				for i := 0; i < 60; i++ {
-					fmt.Fprintf(&builder, "[09:%02d:00.000] %d %d\n", i, i+1, (i%2)+1)
+					fmt.Fprintf(&builder, "[09:%02d:00.000] %d %d\n", i, withoutParams[i%len(withoutParams)], (i%2)+1)
 				}
 				return builder.String()
 			}(),
@@ -278,10 +288,9 @@ func TestParseEvents(t *testing.T) {
 				events := make([]Event, 60)
 				for i := 0; i < 60; i++ {
 					events[i] = Event{
-						Time:         fmt.Sprintf("09:%02d:00.000", i),
-						EventID:      i + 1,
+						Time:         clock.MustParse(fmt.Sprintf("09:%02d:00.000", i)),
+						EventID:      withoutParams[i%len(withoutParams)],
 						CompetitorID: (i % 2) + 1,
-						ExtraParams:  "",
 					}
 				}
 				return events
@@ -290,12 +299,11 @@ func TestParseEvents(t *testing.T) {
 				byTime := make(map[string]Event)
 				for i := 0; i < 60; i++ {
 					event := Event{
-						Time:         fmt.Sprintf("09:%02d:00.000", i),
-						EventID:      i + 1,
+						Time:         clock.MustParse(fmt.Sprintf("09:%02d:00.000", i)),
+						EventID:      withoutParams[i%len(withoutParams)],
 						CompetitorID: (i % 2) + 1,
-						ExtraParams:  "",
 					}
-					byTime[event.Time] = event
+					byTime[event.Time.String()] = event
 				}
 				return byTime
 			}(),
@@ -303,10 +311,9 @@ func TestParseEvents(t *testing.T) {
 				byComp := make(map[int][]Event)
 				for i := 0; i < 60; i++ {
 					event := Event{
-						Time:         fmt.Sprintf("09:%02d:00.000", i),
-						EventID:      i + 1,
+						Time:         clock.MustParse(fmt.Sprintf("09:%02d:00.000", i)),
+						EventID:      withoutParams[i%len(withoutParams)],
 						CompetitorID: (i % 2) + 1,
-						ExtraParams:  "",
 					}
 					byComp[event.CompetitorID] = append(byComp[event.CompetitorID], event)
 				}
@@ -338,7 +345,14 @@ func TestParseEvents(t *testing.T) {
 				defer file.Close()
 			}
 
-			store := ParseEvents(ctx, file)
+			store, parseErrors := ParseEvents(ctx, file, Options{})
+			errLines := []int{}
+			for _, parseErr := range parseErrors {
+				errLines = append(errLines, parseErr.Line)
+			}
+			if len(errLines) != len(tt.wantErrs) || !slices.Equal(errLines, tt.wantErrs) {
+				t.Errorf("ParseEvents() error lines = %v, want %v", errLines, tt.wantErrs)
+			}
 			if (store == nil) != tt.wantNil {
 				t.Errorf("ParseEvents() store = %v, wantNil %v", store, tt.wantNil)
 			}
@@ -366,17 +380,80 @@ func TestParseEvents(t *testing.T) {
 	}
 }
 
+func TestParseEventsMidnight(t *testing.T) {
+	ctx := context.Background()
+
+	tmpfile, err := os.CreateTemp("", "events*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.WriteString("[23:59:58.000] 4 1\n[00:00:02.000] 10 1\n"); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	if _, err := tmpfile.Seek(0, 0); err != nil {
+		t.Fatalf("Failed to rewind temp file: %v", err)
+	}
+	defer tmpfile.Close()
+
+	store, _ := ParseEvents(ctx, tmpfile, Options{})
+	if len(store.events) != 2 {
+		t.Fatalf("ParseEvents() events = %v, want 2 events", store.events)
+	}
+	if got := store.events[1].Time.Sub(store.events[0].Time); got != 4*time.Second {
+		t.Errorf("lap over midnight = %v, want %v", got, 4*time.Second)
+	}
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+
+	tmpfile, err := os.CreateTemp("", "events*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name())
+	input := `[09:59:03.872] 10 1
+[09:59:03.872] 11 1 Lost in the forest
+[09:30:01.005] 4 2
+[09:30:01.005] 4 3
+`
+	if _, err := tmpfile.WriteString(input); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	if _, err := tmpfile.Seek(0, 0); err != nil {
+		t.Fatalf("Failed to rewind temp file: %v", err)
+	}
+	defer tmpfile.Close()
+
+	want := []Event{
+		{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 2},
+		{Time: clock.MustParse("09:30:01.005"), EventID: 4, CompetitorID: 3},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
+	}
+
+	store, _ := ParseEvents(ctx, tmpfile, Options{})
+	got := slices.Collect(store.All())
+	if !slices.Equal(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	if store.Len() != len(want) {
+		t.Errorf("Len() = %d, want %d", store.Len(), len(want))
+	}
+}
+
 func TestByTime(t *testing.T) {
 	store := &EventStore{
 		events: []Event{
-			{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-			{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+			{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 		},
 	}
 
 	want := map[string]Event{
-		"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-		"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+		"09:05:59.867": {Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+		"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 	}
 
 	got := store.ByTime()
@@ -388,19 +465,19 @@ func TestByTime(t *testing.T) {
 func TestByCompetitor(t *testing.T) {
 	store := &EventStore{
 		events: []Event{
-			{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-			{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
-			{Time: "09:16:00.000", EventID: 1, CompetitorID: 2, ExtraParams: ""},
+			{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+			{Time: clock.MustParse("09:16:00.000"), EventID: 1, CompetitorID: 2},
 		},
 	}
 
 	want := map[int][]Event{
 		1: {
-			{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
-			{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+			{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 		},
 		2: {
-			{Time: "09:16:00.000", EventID: 1, CompetitorID: 2, ExtraParams: ""},
+			{Time: clock.MustParse("09:16:00.000"), EventID: 1, CompetitorID: 2},
 		},
 	}
 
@@ -410,10 +487,34 @@ func TestByCompetitor(t *testing.T) {
 	}
 }
 
+func TestInsert(t *testing.T) {
+	store := &EventStore{
+		events: []Event{
+			{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+			{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+			{Time: clock.MustParse("10:00:00.000"), EventID: 10, CompetitorID: 2},
+		},
+	}
+
+	store.Insert(Event{Time: clock.MustParse("09:59:03.872"), EventID: 33, CompetitorID: 1})
+	store.Insert(Event{Time: clock.MustParse("09:00:00.000"), EventID: 32, CompetitorID: 3})
+
+	want := []Event{
+		{Time: clock.MustParse("09:00:00.000"), EventID: 32, CompetitorID: 3},
+		{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 10, CompetitorID: 1},
+		{Time: clock.MustParse("09:59:03.872"), EventID: 33, CompetitorID: 1},
+		{Time: clock.MustParse("10:00:00.000"), EventID: 10, CompetitorID: 2},
+	}
+	if !slices.Equal(store.events, want) {
+		t.Errorf("Insert() events = %v, want %v", store.events, want)
+	}
+}
+
 func TestSortMapByKey(t *testing.T) {
 	eventsMap := map[string]Event{
-		"09:15:00.841": {Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000"},
-		"09:05:59.867": {Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
+		"09:15:00.841": {Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
+		"09:05:59.867": {Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
 	}
 
 	want := []string{"09:05:59.867", "09:15:00.841"}
@@ -428,61 +529,418 @@ func TestParseEvent(t *testing.T) {
 		ctx context.Context
 	}
 
-	ctx := context.WithValue(context.Background(), key, zap.NewNop())
+	ctx := context.Background()
 
 	tests := []struct {
-		name string
-		line string
-		want Event
+		name       string
+		line       string
+		want       Event
+		wantErr    error
+		wantColumn int
 	}{
 		{
 			name: "valid event",
 			line: "[09:05:59.867] 1 1",
-			want: Event{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: ""},
+			want: Event{Time: clock.MustParse("09:05:59.867"), EventID: 1, CompetitorID: 1},
 		},
 		{
-			name: "valid event with extra params",
-			line: "[09:15:00.841] 2 1 09:30:00.000 some text",
-			want: Event{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: "09:30:00.000 some text"},
+			name: "valid start time",
+			line: "[09:15:00.841] 2 1 09:30:00.000",
+			want: Event{Time: clock.MustParse("09:15:00.841"), EventID: 2, CompetitorID: 1, StartTime: clock.MustParse("09:30:00.000")},
 		},
 		{
-			name: "invalid time format",
-			line: "[invalid] 1 1",
-			want: Event{},
+			name: "valid firing range",
+			line: "[09:49:31.659] 5 1 2",
+			want: Event{Time: clock.MustParse("09:49:31.659"), EventID: 5, CompetitorID: 1, FiringRange: 2},
 		},
 		{
-			name: "missing closing bracket",
-			line: "[09:05:59.867 1 1",
-			want: Event{},
+			name: "valid target",
+			line: "[09:49:33.123] 6 1 5",
+			want: Event{Time: clock.MustParse("09:49:33.123"), EventID: 6, CompetitorID: 1, Target: 5},
 		},
 		{
-			name: "invalid event ID",
-			line: "[09:05:59.867] invalid 1",
-			want: Event{},
+			name: "valid comment",
+			line: "[09:59:05.321] 11 1 Lost in the forest",
+			want: Event{Time: clock.MustParse("09:59:05.321"), EventID: 11, CompetitorID: 1, Comment: "Lost in the forest"},
 		},
 		{
-			name: "invalid competitor ID",
-			line: "[09:05:59.867] 1 invalid",
-			want: Event{},
+			name:       "start time with extra text",
+			line:       "[09:15:00.841] 2 1 09:30:00.000 some text",
+			want:       Event{},
+			wantErr:    ErrUnexpectedParameter,
+			wantColumn: 33,
 		},
 		{
-			name: "missing fields",
-			line: "[09:05:59.867] 1",
-			want: Event{},
+			name:       "bad start time",
+			line:       "[09:15:00.841] 2 1 9:30",
+			want:       Event{},
+			wantErr:    ErrBadParameter,
+			wantColumn: 20,
+		},
+		{
+			name:       "firing range not a number",
+			line:       "[09:49:31.659] 5 1 abc",
+			want:       Event{},
+			wantErr:    ErrBadParameter,
+			wantColumn: 20,
+		},
+		{
+			name:       "firing range out of range",
+			line:       "[09:49:31.659] 5 1 3",
+			want:       Event{},
+			wantErr:    ErrBadParameter,
+			wantColumn: 20,
+		},
+		{
+			name:       "target out of range",
+			line:       "[09:49:33.123] 6 1 9",
+			want:       Event{},
+			wantErr:    ErrBadParameter,
+			wantColumn: 20,
+		},
+		{
+			name:       "param on event without params",
+			line:       "[09:30:01.005] 4 1 now",
+			want:       Event{},
+			wantErr:    ErrUnexpectedParameter,
+			wantColumn: 20,
+		},
+		{
+			name:       "invalid time format",
+			line:       "[invalid] 1 1",
+			want:       Event{},
+			wantErr:    ErrBadTime,
+			wantColumn: 2,
 		},
 		{
-			name: "no prefix",
-			line: "09:05:59.867 1 1",
-			want: Event{},
+			name:       "missing closing bracket",
+			line:       "[09:05:59.867 1 1",
+			want:       Event{},
+			wantErr:    ErrBadTime,
+			wantColumn: 1,
+		},
+		{
+			name:       "invalid event ID",
+			line:       "[09:05:59.867] invalid 1",
+			want:       Event{},
+			wantErr:    ErrBadEventID,
+			wantColumn: 16,
+		},
+		{
+			name:       "invalid competitor ID",
+			line:       "[09:05:59.867] 1 invalid",
+			want:       Event{},
+			wantErr:    ErrBadCompetitorID,
+			wantColumn: 18,
+		},
+		{
+			name:       "missing fields",
+			line:       "[09:05:59.867] 1",
+			want:       Event{},
+			wantErr:    ErrMissingFields,
+			wantColumn: 17,
+		},
+		{
+			name: "handover",
+			line: "[09:05:59.867] 12 1",
+			want: Event{Time: clock.MustParse("09:05:59.867"), EventID: 12, CompetitorID: 1},
+		},
+		{
+			name:       "unknown event ID",
+			line:       "[09:05:59.867] 13 1",
+			want:       Event{},
+			wantErr:    ErrUnknownEvent,
+			wantColumn: 16,
+		},
+		{
+			name:       "outgoing event in input",
+			line:       "[09:05:59.867] 33 1",
+			want:       Event{},
+			wantErr:    ErrUnknownEvent,
+			wantColumn: 16,
+		},
+		{
+			name:       "missing start time",
+			line:       "[09:15:00.841] 2 1   ",
+			want:       Event{},
+			wantErr:    ErrMissingParameter,
+			wantColumn: 19,
+		},
+		{
+			name:       "leading spaces",
+			line:       "  [09:05:59.867] x 1",
+			want:       Event{},
+			wantErr:    ErrBadEventID,
+			wantColumn: 18,
+		},
+		{
+			name:       "no prefix",
+			line:       "09:05:59.867 1 1",
+			want:       Event{},
+			wantErr:    ErrBadTime,
+			wantColumn: 1,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := parseEvent(ctx, tt.line)
+			got, err := parseEvent(ctx, tt.line, Options{FiringLines: 2})
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("parseEvent() = %v, want %v", got, tt.want)
 			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("parseEvent() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseEvent() error = %v, want %v", err, tt.wantErr)
+			}
+			if err.Column != tt.wantColumn || err.Raw != tt.line {
+				t.Errorf("parseEvent() error at column %d of %q, want column %d of %q", err.Column, err.Raw, tt.wantColumn, tt.line)
+			}
 		})
 	}
 }
+
+func TestParseEventsStrict(t *testing.T) {
+	ctx := context.Background()
+
+	tmpfile, err := os.CreateTemp("", "events*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name())
+	input := `[09:05:59.867] 1 1
+[09:15:00.841] 2 1
+[09:16:00.000] 1 2
+[09:17:00.000] x 2
+`
+	if _, err := tmpfile.WriteString(input); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	defer tmpfile.Close()
+
+	for _, strict := range []bool{false, true} {
+		if _, err := tmpfile.Seek(0, 0); err != nil {
+			t.Fatalf("Failed to rewind temp file: %v", err)
+		}
+
+		store, parseErrors := ParseEvents(ctx, tmpfile, Options{Strict: strict})
+		wantErrs, wantEvents := 2, 2
+		if strict {
+			wantErrs, wantEvents = 1, 1
+		}
+		if len(parseErrors) != wantErrs {
+			t.Fatalf("strict=%v: ParseEvents() errors = %v, want %d", strict, parseErrors, wantErrs)
+		}
+		if store.Len() != wantEvents {
+			t.Errorf("strict=%v: ParseEvents() events = %d, want %d", strict, store.Len(), wantEvents)
+		}
+
+		first := parseErrors[0]
+		if first.Line != 2 || !errors.Is(first, ErrMissingParameter) {
+			t.Errorf("strict=%v: first error = %v, want line 2 %v", strict, first, ErrMissingParameter)
+		}
+	}
+}
+
+func TestParseEventsLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	input := "[09:05:59.867] 1 1\n[09:15:00.841] 11 1 " + strings.Repeat("x", bufio.MaxScanTokenSize) + "\n[09:16:00.000] 1 2\n"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, strict := range []bool{false, true} {
+		eventsFile, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, parseErrors := ParseEvents(context.Background(), eventsFile, Options{Strict: strict})
+		eventsFile.Close()
+
+		if len(parseErrors) != 1 {
+			t.Fatalf("strict=%v: ParseEvents() errors = %v, want one", strict, parseErrors)
+		}
+		if got := parseErrors[0]; got.Line != 2 || !errors.Is(got, ErrRead) || !errors.Is(got, bufio.ErrTooLong) {
+			t.Errorf("strict=%v: error = %v, want line 2 %v", strict, got, bufio.ErrTooLong)
+		}
+		if store.Len() != 1 {
+			t.Errorf("strict=%v: ParseEvents() events = %d, want the one before the long line", strict, store.Len())
+		}
+	}
+}
+
+func TestParseEventTargets(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := parseEvent(ctx, "[09:49:33.123] 6 1 3", Options{Targets: 3}); err != nil {
+		t.Errorf("parseEvent() target 3 of 3 error = %v", err)
+	}
+	if _, err := parseEvent(ctx, "[09:49:33.123] 6 1 4", Options{Targets: 3}); !errors.Is(err, ErrBadParameter) {
+		t.Errorf("parseEvent() target 4 of 3 error = %v, want %v", err, ErrBadParameter)
+	}
+	if _, err := parseEvent(ctx, "[09:49:33.123] 6 1 5", Options{}); err != nil {
+		t.Errorf("parseEvent() target 5 with default targets error = %v", err)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		eventID  int
+		name     string
+		incoming bool
+	}{
+		{eventID: Registered, name: "Registered", incoming: true},
+		{eventID: CannotContinue, name: "CannotContinue", incoming: true},
+		{eventID: HandedOver, name: "HandedOver", incoming: true},
+		{eventID: Disqualified, name: "Disqualified"},
+		{eventID: Finished, name: "Finished"},
+		{eventID: 99, name: "Unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Name(tt.eventID); got != tt.name {
+				t.Errorf("Name(%d) = %q, want %q", tt.eventID, got, tt.name)
+			}
+			if got := Incoming(tt.eventID); got != tt.incoming {
+				t.Errorf("Incoming(%d) = %v, want %v", tt.eventID, got, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	input := `[23:59:59.000] 4 2
+[23:59:58.000] 4 1
+bad line
+[00:00:01.000] 10 1
+`
+
+	type item struct {
+		event Event
+		line  int
+	}
+	type content struct {
+		name   string
+		strict bool
+		want   []item
+	}
+
+	tests := []content{
+		{
+			name: "input order with rollover",
+			want: []item{
+				{event: Event{Time: clock.MustParse("23:59:59.000"), EventID: 4, CompetitorID: 2}},
+				{event: Event{Time: clock.MustParse("23:59:58.000"), EventID: 4, CompetitorID: 1}},
+				{line: 3},
+				{event: Event{Time: clock.MustParse("00:00:01.000").Add(24 * time.Hour), EventID: 10, CompetitorID: 1}},
+			},
+		},
+		{
+			name:   "strict",
+			strict: true,
+			want: []item{
+				{event: Event{Time: clock.MustParse("23:59:59.000"), EventID: 4, CompetitorID: 2}},
+				{event: Event{Time: clock.MustParse("23:59:58.000"), EventID: 4, CompetitorID: 1}},
+				{line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []item
+			for event, parseErr := range Stream(ctx, strings.NewReader(input), Options{Strict: tt.strict}) {
+				if parseErr != nil {
+					got = append(got, item{line: parseErr.Line})
+					continue
+				}
+				got = append(got, item{event: event})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Stream() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events")
+	if err := os.WriteFile(path, []byte("[09:05:59.867] 1 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eventsFile, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eventsFile.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	next, stop := iter.Pull2(Stream(ctx, Tail(ctx, eventsFile, time.Millisecond), Options{}))
+	defer stop()
+
+	if event, _, ok := next(); !ok || event.EventID != Registered {
+		t.Fatalf("first event = %v, %v, want the registration", event, ok)
+	}
+
+	writer, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.WriteString("[09:15:00.841] 2 1 09:30:00.000\n"); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	if event, _, ok := next(); !ok || event.EventID != StartTimeDrawn {
+		t.Fatalf("appended event = %v, %v, want the draw", event, ok)
+	}
+
+	cancel()
+	if _, _, ok := next(); ok {
+		t.Error("Stream() went on after the context was done")
+	}
+}
+
+func TestTailReadError(t *testing.T) {
+	errDisk := errors.New("disk gone")
+	source := io.MultiReader(strings.NewReader("[09:05:59.867] 1 1\n"), iotest.ErrReader(errDisk))
+
+	var got []error
+	for _, parseErr := range Stream(context.Background(), Tail(context.Background(), source, time.Millisecond), Options{}) {
+		if parseErr != nil {
+			got = append(got, parseErr)
+		}
+	}
+	if len(got) != 1 || !errors.Is(got[0], ErrRead) || !errors.Is(got[0], errDisk) {
+		t.Errorf("Stream() errors = %v, want the read error", got)
+	}
+}
+
+func TestTailPartialLine(t *testing.T) {
+	source, writer := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := Tail(ctx, source, time.Millisecond)
+
+	go func() {
+		writer.Write([]byte("[09:05:59.867] 1 1\n[09:15:00.841] 2 1"))
+		writer.Write([]byte(" 09:30:00.000\n[09:30:01.005] 4"))
+		cancel()
+		writer.Close()
+	}()
+
+	got, err := io.ReadAll(lines)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "[09:05:59.867] 1 1\n[09:15:00.841] 2 1 09:30:00.000\n"; string(got) != want {
+		t.Errorf("Tail() = %q, want %q", got, want)
+	}
+}
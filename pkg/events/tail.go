@@ -0,0 +1,61 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+// tail reads a growing source, waiting for more data at its end
+type tail struct {
+	ctx      context.Context
+	r        io.Reader
+	interval time.Duration
+	buf      [4096]byte
+	// lines are complete lines not returned yet, pending is the line still being written
+	lines   []byte
+	pending []byte
+	err     error
+}
+
+// Tail follows r like tail -f: at the end of r it polls every interval for new
+// data instead of returning io.EOF, until ctx is done. Only complete lines are
+// returned, a line still being written when ctx is done is dropped. Feed it to
+// Stream to parse an events file while the race is still writing it.
+func Tail(ctx context.Context, r io.Reader, interval time.Duration) io.Reader {
+	return &tail{ctx: ctx, r: r, interval: interval}
+}
+
+func (t *tail) Read(p []byte) (int, error) {
+	for len(t.lines) == 0 && t.err == nil {
+		n, err := t.r.Read(t.buf[:])
+		t.pending = append(t.pending, t.buf[:n]...)
+		if i := bytes.LastIndexByte(t.pending, '\n'); i >= 0 {
+			t.lines, t.pending = t.pending[:i+1], slices.Clone(t.pending[i+1:])
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.err = err
+		}
+		if n > 0 || t.err != nil {
+			continue
+		}
+
+		timer := time.NewTimer(t.interval)
+		select {
+		case <-t.ctx.Done():
+			timer.Stop()
+			return 0, io.EOF
+		case <-timer.C:
+		}
+	}
+
+	if len(t.lines) == 0 {
+		return 0, t.err
+	}
+	n := copy(p, t.lines)
+	t.lines = t.lines[n:]
+	return n, nil
+}